@@ -0,0 +1,176 @@
+package gotwant
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	hexdumpWidth   = 16 // bytes per line
+	hexdumpContext = 1  // lines before the first difference
+	hexdumpLines   = 4  // lines printed
+)
+
+// Bytes constructs a byte-comparation test case.
+// got and want are []byte, string or io.Reader, which is read lazily until the first difference.
+//
+// The first differing offset is reported, and bytes around it are printed in aligned hexdumps.
+func Bytes(got, want interface{}, opts ...Option) *bytesCase {
+	c := &bytesCase{
+		Got:  got,
+		Want: want,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type bytesCase struct {
+	Got  interface{} // what you got.
+	Want interface{} // what you expected.
+
+	Desc string // a line description
+}
+
+func (c *bytesCase) SetFmt(format string) {
+}
+
+func (c *bytesCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *bytesCase) Test(t T) {
+	t.Helper()
+
+	gr, err := byteReader(c.Got)
+	if err != nil {
+		t.Errorf("%s\ngot %v", c.Desc, err)
+		return
+	}
+	wr, err := byteReader(c.Want)
+	if err != nil {
+		t.Errorf("%s\nwant %v", c.Desc, err)
+		return
+	}
+
+	// bytes before offset, in common
+	var hist []byte
+	offset := 0
+
+	var gotWin, wantWin []byte
+	var what string
+	for {
+		gb, gerr := gr.ReadByte()
+		wb, werr := wr.ReadByte()
+		if gerr != nil && gerr != io.EOF {
+			t.Errorf("%s\ngot an error at offset %d: %v", c.Desc, offset, gerr)
+			return
+		}
+		if werr != nil && werr != io.EOF {
+			t.Errorf("%s\nwant an error at offset %d: %v", c.Desc, offset, werr)
+			return
+		}
+
+		switch {
+		case gerr == io.EOF && werr == io.EOF:
+			return
+		case gerr == io.EOF:
+			what = "got ended"
+			wantWin = []byte{wb}
+		case werr == io.EOF:
+			what = "want ended"
+			gotWin = []byte{gb}
+		case gb != wb:
+			what = "first difference"
+			gotWin, wantWin = []byte{gb}, []byte{wb}
+		default:
+			hist = append(hist, gb)
+			if keep := hexdumpWidth * (hexdumpContext + 1); len(hist) > 2*keep {
+				hist = hist[len(hist)-keep:]
+			}
+			offset++
+			continue
+		}
+		break
+	}
+
+	start := offset - offset%hexdumpWidth - hexdumpWidth*hexdumpContext
+	if start < 0 {
+		start = 0
+	}
+	before := hist[len(hist)-(offset-start):]
+	end := start + hexdumpWidth*hexdumpLines
+
+	gotWin = append(append([]byte{}, before...), readUpTo(gr, gotWin, end-offset)...)
+	wantWin = append(append([]byte{}, before...), readUpTo(wr, wantWin, end-offset)...)
+
+	t.Errorf("%s\n%s at offset %d (%#x)\n%s\n%s", c.Desc, what, offset, offset,
+		indent("got:  "+hexdump(start, gotWin)), indent("want: "+hexdump(start, wantWin)))
+}
+
+func byteReader(v interface{}) (*bufio.Reader, error) {
+	switch b := v.(type) {
+	case []byte:
+		return bufio.NewReader(bytes.NewReader(b)), nil
+	case string:
+		return bufio.NewReader(strings.NewReader(b)), nil
+	case io.Reader:
+		return bufio.NewReader(b), nil
+	default:
+		return nil, fmt.Errorf("%T, not bytes", v)
+	}
+}
+
+// readUpTo reads r until buf has n bytes or EOF.
+func readUpTo(r *bufio.Reader, buf []byte, n int) []byte {
+	for len(buf) < n {
+		b, err := r.ReadByte()
+		if err != nil {
+			break
+		}
+		buf = append(buf, b)
+	}
+	return buf
+}
+
+// hexdump prints b like `hexdump -C`, with offsets from start.
+// Partial lines are padded, so that dumps are aligned.
+func hexdump(start int, b []byte) string {
+	if len(b) == 0 {
+		return "(empty)"
+	}
+
+	sb := &strings.Builder{}
+	for i := 0; i < len(b); i += hexdumpWidth {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		line := b[i:min(i+hexdumpWidth, len(b))]
+
+		fmt.Fprintf(sb, "%08x ", start+i)
+		for j := 0; j < hexdumpWidth; j++ {
+			if j%8 == 0 {
+				sb.WriteString(" ")
+			}
+			if j < len(line) {
+				fmt.Fprintf(sb, "%02x ", line[j])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+
+		sb.WriteString(" |")
+		for _, ch := range line {
+			if ch < 0x20 || 0x7e < ch {
+				ch = '.'
+			}
+			sb.WriteByte(ch)
+		}
+		sb.WriteString("|")
+	}
+	return sb.String()
+}
@@ -0,0 +1,157 @@
+package gotwant
+
+import (
+	"reflect"
+	"time"
+)
+
+// chanOp is a kind of channel test.
+type chanOp int
+
+const (
+	opReceive chanOp = iota
+	opNotReceive
+	opClosed
+	opDrain
+)
+
+// Timeout sets how long channel cases wait, or a grace period of NoLeaks. default: TimeoutDefault
+func Timeout(d time.Duration) Option {
+	return func(c TestCase) {
+		if tc, ok := c.(interface{ SetTimeout(time.Duration) }); ok {
+			tc.SetTimeout(d)
+		}
+	}
+}
+
+// Receive constructs a test case of receiving want from ch within the timeout.
+func Receive[E any](ch <-chan E, want E, opts ...Option) *chanCase[E] {
+	return newChanCase(opReceive, ch, []E{want}, opts)
+}
+
+// NotReceive constructs a test case of receiving nothing from ch within the timeout.
+// Closing ch is not regarded as receiving.
+func NotReceive[E any](ch <-chan E, opts ...Option) *chanCase[E] {
+	return newChanCase(opNotReceive, ch, nil, opts)
+}
+
+// Closed constructs a test case of ch being closed within the timeout, without any value left.
+func Closed[E any](ch <-chan E, opts ...Option) *chanCase[E] {
+	return newChanCase(opClosed, ch, nil, opts)
+}
+
+// Drain constructs a test case of receiving values from ch until it is closed within the timeout,
+// and comparing them with want.
+func Drain[E any](ch <-chan E, want []E, opts ...Option) *chanCase[E] {
+	return newChanCase(opDrain, ch, want, opts)
+}
+
+func newChanCase[E any](op chanOp, ch <-chan E, want []E, opts []Option) *chanCase[E] {
+	c := &chanCase[E]{
+		Ch:      ch,
+		Want:    want,
+		Timeout: TimeoutDefault,
+		op:      op,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type chanCase[E any] struct {
+	Ch   <-chan E // what you got from.
+	Want []E      // what you expected. (a value for Receive)
+
+	Timeout time.Duration // how long to wait.  default: TimeoutDefault
+	Fmt     string        // used in t.Errorf displaying got and want.  default: FmtDefault
+	Desc    string        // a line description
+
+	op chanOp
+
+	checker *Checker // set by Checker.Options
+}
+
+func (c *chanCase[E]) SetFmt(format string) {
+	c.Fmt = format
+}
+
+func (c *chanCase[E]) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *chanCase[E]) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
+func (c *chanCase[E]) SetTimeout(d time.Duration) {
+	c.Timeout = d
+}
+
+func (c *chanCase[E]) Test(t T) {
+	t.Helper()
+
+	valfmt := c.Fmt
+	if valfmt == "" {
+		valfmt = c.checker.defaultFmt()
+	}
+
+	timer := time.NewTimer(c.Timeout)
+	defer timer.Stop()
+
+	switch c.op {
+	case opReceive:
+		select {
+		case v, ok := <-c.Ch:
+			if !ok {
+				t.Errorf("%s\ngot closed.\n%s", c.Desc, indent("want: "+c.checker.sprint(valfmt, c.Want[0])))
+				return
+			}
+			if !reflect.DeepEqual(v, c.Want[0]) {
+				gotS, wantS := c.checker.sprintPair(valfmt, v, c.Want[0])
+				t.Errorf("%s\n%s\n%s", c.Desc, indent("got:  "+gotS), indent("want: "+wantS))
+			}
+		case <-timer.C:
+			t.Errorf("%s\ngot NO value in %v.\n%s", c.Desc, c.Timeout, indent("want: "+c.checker.sprint(valfmt, c.Want[0])))
+		}
+
+	case opNotReceive:
+		select {
+		case v, ok := <-c.Ch:
+			if ok {
+				t.Errorf("%s\ngot a value, want NO value in %v.\n%s", c.Desc, c.Timeout, indent("got:  "+c.checker.sprint(valfmt, v)))
+			}
+		case <-timer.C:
+		}
+
+	case opClosed:
+		select {
+		case v, ok := <-c.Ch:
+			if ok {
+				t.Errorf("%s\ngot a value, want closed.\n%s", c.Desc, indent("got:  "+c.checker.sprint(valfmt, v)))
+			}
+		case <-timer.C:
+			t.Errorf("%s\ngot NOT closed in %v.", c.Desc, c.Timeout)
+		}
+
+	case opDrain:
+		got := []E{}
+		for {
+			select {
+			case v, ok := <-c.Ch:
+				if ok {
+					got = append(got, v)
+					continue
+				}
+				if !reflect.DeepEqual(got, c.Want) && (len(got) != 0 || len(c.Want) != 0) {
+					gotS, wantS := c.checker.sprintPair(valfmt, got, c.Want)
+					t.Errorf("%s\n%s\n%s", c.Desc, indent("got:  "+gotS), indent("want: "+wantS))
+				}
+			case <-timer.C:
+				gotS, wantS := c.checker.sprintPair(valfmt, got, c.Want)
+				t.Errorf("%s\ngot NOT closed in %v.\n%s\n%s", c.Desc, c.Timeout, indent("got:  "+gotS), indent("want: "+wantS))
+			}
+			return
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package gotwant
 
 import (
-	"fmt"
 	"reflect"
+
+	"github.com/shu-go/gotwant/diff"
 )
 
 // Case constructs a value-comaration test case.
@@ -10,6 +11,7 @@ func Case(got, want interface{}, opts ...Option) *cmpCase {
 	c := &cmpCase{
 		Got:  got,
 		Want: want,
+		Diff: DiffDefault,
 	}
 	for _, o := range opts {
 		o(c)
@@ -23,6 +25,9 @@ type cmpCase struct {
 
 	Fmt  string // used in t.Errorf displaying got and want.  default: FmtDefault
 	Desc string // a line description
+	Diff bool   // append a diff of got and want.  default: DiffDefault
+
+	checker *Checker // set by Checker.Options
 }
 
 func (c *cmpCase) SetFmt(format string) {
@@ -33,24 +38,65 @@ func (c *cmpCase) SetDesc(desc string) {
 	c.Desc = desc
 }
 
+func (c *cmpCase) SetDiff(enabled bool) {
+	c.Diff = enabled
+}
+
+func (c *cmpCase) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
 func (c *cmpCase) Test(t T) {
 	t.Helper()
 
-	if !reflect.DeepEqual(c.Got, c.Want) {
+	equal, compared := c.checker.comparer(c.Got, c.Want)
+	if !compared {
+		equal = reflect.DeepEqual
+	}
+
+	if !equal(c.Got, c.Want) {
 		valfmt := c.Fmt
 		if valfmt == "" {
-			valfmt = FmtDefault
-			for _, f := range []string{FmtDefault, "%#v", "%T"} {
-				fmted1, fmted2 := fmt.Sprintf(f, c.Got), fmt.Sprintf(f, c.Want)
+			valfmt = c.checker.defaultFmt()
+			for _, f := range []string{valfmt, "%#v", "%T"} {
+				fmted1, fmted2 := sprint(f, c.Got), sprint(f, c.Want)
 				if fmted1 != fmted2 {
 					valfmt = f
+					if f != "%T" && pretty(c.Got) != pretty(c.Want) {
+						if (len(fmted1) > prettyWidth || len(fmted2) > prettyWidth) && (isComposite(c.Got) || isComposite(c.Want)) {
+							// too long to read in a line
+							valfmt = FmtPretty
+						} else if hasNestedPointer(c.Got) || hasNestedPointer(c.Want) {
+							// addresses are meaningless
+							valfmt = FmtPretty
+						}
+					}
 					break
 				}
 			}
 		}
 
-		got := indent(fmt.Sprintf(fmt.Sprintf("got:  %s", valfmt), c.Got))
-		want := indent(fmt.Sprintf(fmt.Sprintf("want: %s", valfmt), c.Want))
+		gotS, wantS := sprint(valfmt, c.Got), sprint(valfmt, c.Want)
+		gotT, wantT := c.checker.truncatePair(gotS, wantS)
+		got := indent("got:  " + gotT)
+		want := indent("want: " + wantT)
+		if gotS == wantS && !compared {
+			// printed identically
+			if reason := explain(c.Got, c.Want); reason != "" {
+				t.Errorf("%s\n%s\n%s\n%s", c.Desc, got, want, indent("reason: "+reason))
+				return
+			}
+		}
+		if c.Diff && gotS != wantS {
+			diffs := diff.Compute(gotS, wantS, diff.Word)
+			at := 0 // the first difference
+			if len(diffs) > 0 && diffs[0].Type == diff.Equal {
+				at = len(diffs[0].Text)
+			}
+			d := indent("diff: " + c.checker.truncateAt(diff.PlainText(diffs), at))
+			t.Errorf("%s\n%s\n%s\n%s", c.Desc, got, want, d)
+			return
+		}
 		t.Errorf("%s\n%s\n%s", c.Desc, got, want)
 	}
 }
@@ -0,0 +1,290 @@
+package gotwant
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// collOp is a kind of collection comparation.
+type collOp int
+
+const (
+	opContains collOp = iota
+	opElementsMatch
+	opSubset
+	opKeysEqual
+)
+
+// Contains constructs a test case of got containing want.
+// got is a slice, an array, a map (want is a key) or a string (want is a substring or a rune).
+func Contains(got, want interface{}, opts ...Option) *collCase {
+	return newCollCase(opContains, got, want, opts)
+}
+
+// ElementsMatch constructs a test case of got and want having the same elements (and the same number of duplicates) regardless of order.
+// got and want are slices, arrays, maps (elements are keys) or strings (elements are one-rune strings, such as "a").
+func ElementsMatch(got, want interface{}, opts ...Option) *collCase {
+	return newCollCase(opElementsMatch, got, want, opts)
+}
+
+// Subset constructs a test case of all elements of got being in want, regardless of duplicates.
+// got and want are slices, arrays, maps (elements are keys) or strings (elements are one-rune strings, such as "a").
+func Subset(got, want interface{}, opts ...Option) *collCase {
+	return newCollCase(opSubset, got, want, opts)
+}
+
+// KeysEqual constructs a test case of a map got having the same keys as want.
+// want is a map or a slice of keys.
+func KeysEqual(got, want interface{}, opts ...Option) *collCase {
+	return newCollCase(opKeysEqual, got, want, opts)
+}
+
+func newCollCase(op collOp, got, want interface{}, opts []Option) *collCase {
+	c := &collCase{
+		Got:  got,
+		Want: want,
+		op:   op,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type collCase struct {
+	Got  interface{} // what you got.
+	Want interface{} // an element, or elements.
+
+	Fmt  string // used in t.Errorf displaying got and want.  default: FmtDefault
+	Desc string // a line description
+
+	op collOp
+
+	checker *Checker // set by Checker.Options
+}
+
+func (c *collCase) SetFmt(format string) {
+	c.Fmt = format
+}
+
+func (c *collCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *collCase) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
+func (c *collCase) Test(t T) {
+	t.Helper()
+
+	valfmt := c.Fmt
+	if valfmt == "" {
+		valfmt = c.checker.defaultFmt()
+	}
+
+	if c.op == opContains {
+		if s, ok := c.Got.(string); ok {
+			sub, ok := c.Want.(string)
+			if r, isRune := c.Want.(rune); isRune {
+				sub, ok = string(r), true
+			}
+			if ok {
+				if !strings.Contains(s, sub) {
+					t.Errorf("%s\nmissing: %s\n%s", c.Desc, c.checker.sprint(valfmt, c.Want), indent("got:  "+c.checker.sprint(valfmt, c.Got)))
+				}
+				return
+			}
+		}
+
+		elems, err := elementsOf(c.Got)
+		if err != nil {
+			t.Errorf("%s\ngot %v", c.Desc, err)
+			return
+		}
+		if indexOf(elems, c.Want) == -1 {
+			t.Errorf("%s\nmissing: %s\n%s", c.Desc, c.checker.sprint(valfmt, c.Want), indent("got:  "+c.checker.sprint(valfmt, c.Got)))
+		}
+		return
+	}
+
+	if c.op == opKeysEqual && reflect.ValueOf(c.Got).Kind() != reflect.Map {
+		t.Errorf("%s\ngot %T, not a map", c.Desc, c.Got)
+		return
+	}
+
+	gotElems, err := elementsOf(c.Got)
+	if err != nil {
+		t.Errorf("%s\ngot %v", c.Desc, err)
+		return
+	}
+	wantElems, err := elementsOf(c.Want)
+	if err != nil {
+		t.Errorf("%s\nwant %v", c.Desc, err)
+		return
+	}
+
+	var missing, extra []interface{}
+	if c.op == opSubset {
+		for _, g := range gotElems {
+			if indexOf(wantElems, g) == -1 {
+				extra = append(extra, g)
+			}
+		}
+	} else {
+		missing, extra = diffElements(gotElems, wantElems)
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		return
+	}
+
+	var lines []string
+	if len(missing) != 0 {
+		lines = append(lines, "missing: "+c.checker.sprint(valfmt, missing))
+	}
+	if len(extra) != 0 {
+		lines = append(lines, "extra:   "+c.checker.sprint(valfmt, extra))
+	}
+	gotS, wantS := c.checker.sprintPair(valfmt, c.Got, c.Want)
+	t.Errorf("%s\n%s\n%s\n%s", c.Desc, strings.Join(lines, "\n"), indent("got:  "+gotS), indent("want: "+wantS))
+}
+
+// Len constructs a test case of the length of got.
+// got is a slice, an array, a map, a string or a channel.
+func Len(got interface{}, want int, opts ...Option) *lenCase {
+	c := &lenCase{
+		Got:  got,
+		Want: want,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Empty constructs a test case of got being empty; nil, zero length or the zero value.
+func Empty(got interface{}, opts ...Option) *lenCase {
+	c := &lenCase{
+		Got:   got,
+		empty: true,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type lenCase struct {
+	Got  interface{} // what you got.
+	Want int         // the length you expected.
+
+	Fmt  string // used in t.Errorf displaying got.  default: FmtDefault
+	Desc string // a line description
+
+	empty bool // Empty accepts nil and zero values as well
+
+	checker *Checker // set by Checker.Options
+}
+
+func (c *lenCase) SetFmt(format string) {
+	c.Fmt = format
+}
+
+func (c *lenCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *lenCase) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
+func (c *lenCase) Test(t T) {
+	t.Helper()
+
+	valfmt := c.Fmt
+	if valfmt == "" {
+		valfmt = c.checker.defaultFmt()
+	}
+
+	v := reflect.ValueOf(c.Got)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan:
+		if v.Len() != c.Want {
+			t.Errorf("%s\nlen: got %d, want %d\n%s", c.Desc, v.Len(), c.Want, indent("got:  "+c.checker.sprint(valfmt, c.Got)))
+		}
+
+	case reflect.Invalid:
+		if !c.empty && c.Want != 0 {
+			t.Errorf("%s\nlen: got nil, want %d", c.Desc, c.Want)
+		}
+
+	default:
+		if !c.empty {
+			t.Errorf("%s\ngot %T, which has no length", c.Desc, c.Got)
+		} else if !v.IsZero() {
+			t.Errorf("%s\nnot empty\n%s", c.Desc, indent("got:  "+c.checker.sprint(valfmt, c.Got)))
+		}
+	}
+}
+
+// elementsOf returns elements of a slice or an array, keys of a map, or runes of a string as one-rune strings.
+// Keys are sorted by their printed forms.
+func elementsOf(v interface{}) ([]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elems := make([]interface{}, rv.Len())
+		for i := range elems {
+			elems[i] = rv.Index(i).Interface()
+		}
+		return elems, nil
+
+	case reflect.Map:
+		elems := make([]interface{}, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			elems = append(elems, k.Interface())
+		}
+		sort.Slice(elems, func(i, j int) bool {
+			return fmt.Sprintf("%#v", elems[i]) < fmt.Sprintf("%#v", elems[j])
+		})
+		return elems, nil
+
+	case reflect.String:
+		var elems []interface{}
+		for _, r := range rv.String() {
+			elems = append(elems, string(r))
+		}
+		return elems, nil
+
+	case reflect.Invalid:
+		return nil, nil
+
+	default:
+		return nil, fmt.Errorf("%T, not a collection", v)
+	}
+}
+
+// diffElements returns elements of want not in got (missing), and elements of got not in want (extra),
+// counting duplicates.
+func diffElements(got, want []interface{}) (missing, extra []interface{}) {
+	rest := append([]interface{}(nil), want...)
+	for _, g := range got {
+		if i := indexOf(rest, g); i != -1 {
+			rest = append(rest[:i], rest[i+1:]...)
+		} else {
+			extra = append(extra, g)
+		}
+	}
+	return rest, extra
+}
+
+func indexOf(elems []interface{}, v interface{}) int {
+	for i, e := range elems {
+		if reflect.DeepEqual(e, v) {
+			return i
+		}
+	}
+	return -1
+}
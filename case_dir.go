@@ -0,0 +1,236 @@
+package gotwant
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shu-go/gotwant/diff"
+)
+
+// Update enables(disables) the update mode of Dir cases, which rewrites want by got. default: UpdateDefault
+func Update(enabled bool) Option {
+	return func(c TestCase) {
+		if uc, ok := c.(interface{ SetUpdate(bool) }); ok {
+			uc.SetUpdate(enabled)
+		}
+	}
+}
+
+// Prune makes the update mode of Dir cases remove files (and directories left empty) in want not in got.
+func Prune() Option {
+	return func(c TestCase) {
+		if pc, ok := c.(interface{ SetPrune(bool) }); ok {
+			pc.SetPrune(true)
+		}
+	}
+}
+
+// Dir constructs a directory-comparation test case.
+// got and want are directory paths (string) or fs.FS.
+//
+// Missing files, extra files and line diffs of files are reported. Empty directories are ignored.
+// In the update mode, files in want (a path) are rewritten by got.
+// Files not in got (such as .gitkeep) are kept, unless Prune is specified.
+func Dir(got, want interface{}, opts ...Option) *dirCase {
+	c := &dirCase{
+		Got:    got,
+		Want:   want,
+		Update: UpdateDefault,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type dirCase struct {
+	Got  interface{} // what you got. (a path or a fs.FS)
+	Want interface{} // what you expected. (a path or a fs.FS)
+
+	Update bool   // rewrite Want by Got.  default: UpdateDefault
+	Prune  bool   // remove files in Want not in Got, in the update mode
+	Desc   string // a line description
+}
+
+func (c *dirCase) SetFmt(format string) {
+}
+
+func (c *dirCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *dirCase) SetUpdate(enabled bool) {
+	c.Update = enabled
+}
+
+func (c *dirCase) SetPrune(prune bool) {
+	c.Prune = prune
+}
+
+func (c *dirCase) Test(t T) {
+	t.Helper()
+
+	got, err := readTree(c.Got)
+	if err != nil {
+		t.Errorf("%s\ngot %v", c.Desc, err)
+		return
+	}
+
+	if c.Update {
+		if err := c.update(got); err != nil {
+			t.Errorf("%s\nfailed to update: %v", c.Desc, err)
+		}
+		return
+	}
+
+	want, err := readTree(c.Want)
+	if err != nil {
+		t.Errorf("%s\nwant %v", c.Desc, err)
+		return
+	}
+
+	var missing, extra, diffs []string
+	for _, name := range sortedFileNames(want) {
+		if _, found := got[name]; !found {
+			missing = append(missing, name)
+		}
+	}
+	for _, name := range sortedFileNames(got) {
+		w, found := want[name]
+		if !found {
+			extra = append(extra, name)
+			continue
+		}
+
+		g := got[name]
+		if bytes.Equal(g, w) {
+			continue
+		}
+		if !utf8.Valid(g) || !utf8.Valid(w) {
+			diffs = append(diffs, fmt.Sprintf("%s: binary files differ (got %d bytes, want %d bytes)", name, len(g), len(w)))
+			continue
+		}
+		if withEOL(string(g)) == withEOL(string(w)) {
+			diffs = append(diffs, fmt.Sprintf("%s: differs in a newline at end of file", name))
+			continue
+		}
+		d := diff.RenderString(diff.LineRenderer{}, diff.Compute(withEOL(string(g)), withEOL(string(w)), diff.Line))
+		diffs = append(diffs, indent(name+":\n"+strings.TrimSuffix(d, "\n")))
+	}
+	if len(missing) == 0 && len(extra) == 0 && len(diffs) == 0 {
+		return
+	}
+
+	var lines []string
+	for _, name := range missing {
+		lines = append(lines, "missing: "+name)
+	}
+	for _, name := range extra {
+		lines = append(lines, "extra:   "+name)
+	}
+	lines = append(lines, diffs...)
+	t.Errorf("%s\n%s", c.Desc, strings.Join(lines, "\n"))
+}
+
+// update writes got into Want, and removes files in Want not in got if Prune.
+func (c *dirCase) update(got map[string][]byte) error {
+	dir, ok := c.Want.(string)
+	if !ok {
+		return fmt.Errorf("want %T is not a path", c.Want)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for name, content := range got {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if !c.Prune {
+		return nil
+	}
+
+	want, err := readTree(dir)
+	if err != nil {
+		return err
+	}
+	for name := range want {
+		if _, found := got[name]; found {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		// directories left empty
+		for p := filepath.Dir(path); p != dir; p = filepath.Dir(p) {
+			if os.Remove(p) != nil {
+				break // not empty
+			}
+		}
+	}
+	return nil
+}
+
+// readTree reads all regular files in a directory (path or fs.FS) into a map of slash-separated paths.
+func readTree(dir interface{}) (map[string][]byte, error) {
+	var fsys fs.FS
+	switch d := dir.(type) {
+	case string:
+		fsys = os.DirFS(d)
+	case fs.FS:
+		fsys = d
+	default:
+		return nil, fmt.Errorf("%T, not a directory", dir)
+	}
+
+	files := make(map[string][]byte)
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		files[path] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func sortedFileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func withEOL(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,101 @@
+package gotwant
+
+import (
+	"io"
+)
+
+// Unmarshaler decodes a document into v, such as yaml.Unmarshal (gopkg.in/yaml.v3) or toml.Unmarshal (github.com/BurntSushi/toml).
+type Unmarshaler func(data []byte, v interface{}) error
+
+// Document constructs a document-comparation test case, decoding documents by unmarshal (JSON if nil).
+// got and want are documents ([]byte, string or io.Reader), or other values which are marshaled into JSON.
+//
+// Decoded documents are normalized; key order, quoting styles and number representations are ignored.
+// Differences are reported by paths like JSON ($.items[0].name), and got and want are printed in JSON.
+func Document(got, want interface{}, unmarshal Unmarshaler, opts ...Option) *docCase {
+	c := &docCase{
+		Got:       got,
+		Want:      want,
+		Unmarshal: unmarshal,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type docCase struct {
+	Got  interface{} // what you got.
+	Want interface{} // what you expected.
+
+	Unmarshal Unmarshaler // decodes documents
+
+	IgnorePaths []string // paths not compared. ($.a.b, $.items[*].id, ...)
+	AllowExtra  bool     // allow extra fields of objects in Got
+
+	Desc string // a line description
+}
+
+func (c *docCase) SetFmt(format string) {
+}
+
+func (c *docCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *docCase) SetIgnorePaths(paths []string) {
+	c.IgnorePaths = append(c.IgnorePaths, paths...)
+}
+
+func (c *docCase) SetAllowExtra(allow bool) {
+	c.AllowExtra = allow
+}
+
+func (c *docCase) Test(t T) {
+	t.Helper()
+
+	got, err := c.decode(c.Got)
+	if err != nil {
+		t.Errorf("%s\ngot invalid document: %v", c.Desc, err)
+		return
+	}
+	want, err := c.decode(c.Want)
+	if err != nil {
+		t.Errorf("%s\nwant invalid document: %v", c.Desc, err)
+		return
+	}
+
+	cmp := treeComparer{
+		ignore:     parsePaths(c.IgnorePaths),
+		allowExtra: c.AllowExtra,
+	}
+	cmp.test(t, c.Desc, got, want, canonicalJSON)
+}
+
+func (c *docCase) decode(doc interface{}) (interface{}, error) {
+	var data []byte
+	switch d := doc.(type) {
+	case []byte:
+		data = d
+	case string:
+		data = []byte(d)
+	case io.Reader:
+		b, err := io.ReadAll(d)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	default:
+		return decodeJSON(d)
+	}
+
+	if c.Unmarshal == nil {
+		return decodeJSON(data)
+	}
+
+	var v interface{}
+	if err := c.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return normalizeTree(v), nil
+}
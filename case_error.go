@@ -1,7 +1,6 @@
 package gotwant
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
 )
@@ -12,6 +11,8 @@ type errCase struct {
 
 	Fmt  string // used in t.Errorf displaying got and want.  default: FmtDefault
 	Desc string // a line description
+
+	checker *Checker // set by Checker.Options
 }
 
 // Error constructs a error-comaration(nil, string) test case.
@@ -34,12 +35,16 @@ func (c *errCase) SetDesc(desc string) {
 	c.Desc = desc
 }
 
+func (c *errCase) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
 func (c *errCase) Test(t T) {
 	t.Helper()
 
 	valfmt := c.Fmt
 	if valfmt == "" {
-		valfmt = FmtDefault
+		valfmt = c.checker.defaultFmt()
 	}
 
 	if c.Got == nil && c.Want == nil {
@@ -47,8 +52,15 @@ func (c *errCase) Test(t T) {
 	}
 
 	if c.Got == nil {
-		errfmt := fmt.Sprintf("%s\ngot NO error.\nwant error: %s", c.Desc, valfmt)
-		t.Errorf(errfmt, c.Want)
+		t.Errorf("%s\ngot NO error.\nwant error: %s", c.Desc, c.checker.sprint(valfmt, c.Want))
+		return
+	}
+
+	if typ, ok := typedNil(c.Got); ok {
+		if reflect.DeepEqual(c.Got, c.Want) {
+			return
+		}
+		t.Errorf("%s\ngot non-nil error holding nil %s.\nwant error: %s", c.Desc, typeString(typ), c.checker.sprint(valfmt, c.Want))
 		return
 	}
 
@@ -65,6 +77,6 @@ func (c *errCase) Test(t T) {
 		}
 	}
 
-	errfmt := fmt.Sprintf("%s\ngot error:  %s\nwant error: %s", c.Desc, valfmt, valfmt)
-	t.Errorf(errfmt, c.Got, c.Want)
+	gotS, wantS := c.checker.sprintPair(valfmt, c.Got, c.Want)
+	t.Errorf("%s\ngot error:  %s\nwant error: %s", c.Desc, gotS, wantS)
 }
@@ -1,9 +1,5 @@
 package gotwant
 
-import (
-	"fmt"
-)
-
 // ExprCase constructs a test case of given expr.
 func ExprCase(got interface{}, expr bool, opts ...Option) *exprCase {
 	c := &exprCase{
@@ -21,6 +17,8 @@ type exprCase struct {
 	Expr bool        // what you expected with Got.
 
 	Desc string // a line description
+
+	checker *Checker // set by Checker.Options
 }
 
 func (c *exprCase) SetFmt(format string) {
@@ -30,12 +28,15 @@ func (c *exprCase) SetDesc(desc string) {
 	c.Desc = desc
 }
 
+func (c *exprCase) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
 func (c *exprCase) Test(t T) {
 	t.Helper()
 
 	if !c.Expr {
-		valfmt := FmtDefault
-		errfmt := fmt.Sprintf("%s\ngot:  %s", c.Desc, valfmt)
-		t.Errorf(errfmt, c.Got)
+		valfmt := c.checker.defaultFmt()
+		t.Errorf("%s\ngot:  %s", c.Desc, c.checker.sprint(valfmt, c.Got))
 	}
 }
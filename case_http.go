@@ -0,0 +1,190 @@
+package gotwant
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Response is an expected HTTP response.
+// Zero (nil) fields are not compared.
+type Response struct {
+	Status int         // a status code
+	Header http.Header // headers compared; other headers in got are ignored
+	Body   interface{} // a body text (string or []byte)
+	JSON   interface{} // a body JSON document, compared as JSON does
+}
+
+// HTTP constructs a HTTP-response test case.
+// got is a *http.Response or a *httptest.ResponseRecorder (anything having Result() *http.Response).
+//
+// All mismatches of status, headers and body are reported.
+// IgnorePaths and AllowExtra options are applied to JSON.
+func HTTP(got interface{}, want Response, opts ...Option) *httpCase {
+	c := &httpCase{
+		Got:  got,
+		Want: want,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type httpCase struct {
+	Got  interface{} // what you got.
+	Want Response    // what you expected.
+
+	IgnorePaths []string // paths of JSON not compared. ($.a.b, $.items[*].id, ...)
+	AllowExtra  bool     // allow extra fields of JSON objects in Got
+
+	Desc string // a line description
+}
+
+func (c *httpCase) SetFmt(format string) {
+}
+
+func (c *httpCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *httpCase) SetIgnorePaths(paths []string) {
+	c.IgnorePaths = append(c.IgnorePaths, paths...)
+}
+
+func (c *httpCase) SetAllowExtra(allow bool) {
+	c.AllowExtra = allow
+}
+
+func (c *httpCase) Test(t T) {
+	t.Helper()
+
+	var resp *http.Response
+	switch g := c.Got.(type) {
+	case *http.Response:
+		resp = g
+	case interface{ Result() *http.Response }:
+		resp = g.Result()
+	default:
+		t.Errorf("%s\ngot %T, not a HTTP response", c.Desc, c.Got)
+		return
+	}
+
+	var diffs []string
+
+	if c.Want.Status != 0 && resp.StatusCode != c.Want.Status {
+		diffs = append(diffs, fmt.Sprintf("status: got %s, want %s", statusString(resp.StatusCode), statusString(c.Want.Status)))
+	}
+
+	gotHeader, wantHeader := canonicalHeader(resp.Header), canonicalHeader(c.Want.Header)
+	for _, k := range slices.Sorted(maps.Keys(wantHeader)) {
+		want := wantHeader[k]
+		got, found := gotHeader[k]
+		if !found {
+			diffs = append(diffs, fmt.Sprintf("header %s: missing (want %s)", k, headerString(want)))
+		} else if !slices.Equal(got, want) {
+			diffs = append(diffs, fmt.Sprintf("header %s: got %s, want %s", k, headerString(got), headerString(want)))
+		}
+	}
+
+	if c.Want.Body == nil && c.Want.JSON == nil {
+		if len(diffs) != 0 {
+			t.Errorf("%s\n%s", c.Desc, strings.Join(diffs, "\n"))
+		}
+		return
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("%s\ngot an error reading body: %v", c.Desc, err)
+			return
+		}
+		// for later use
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var gotBody, wantBody string
+	if c.Want.JSON != nil {
+		want, err := decodeJSON(c.Want.JSON)
+		if err != nil {
+			t.Errorf("%s\nwant invalid JSON: %v", c.Desc, err)
+			return
+		}
+		wantBody = canonicalJSON(want)
+
+		got, err := decodeJSON(body)
+		if err != nil {
+			diffs = append(diffs, fmt.Sprintf("body: got invalid JSON: %v", err))
+			gotBody = string(body)
+		} else {
+			cmp := treeComparer{
+				ignore:     parsePaths(c.IgnorePaths),
+				allowExtra: c.AllowExtra,
+			}
+			cmp.compare(nil, got, want)
+			for _, d := range cmp.diffs {
+				diffs = append(diffs, "body "+d)
+			}
+			gotBody = canonicalJSON(got)
+		}
+
+	} else {
+		var want []byte
+		switch b := c.Want.Body.(type) {
+		case string:
+			want = []byte(b)
+		case []byte:
+			want = b
+		default:
+			want = []byte(fmt.Sprint(b))
+		}
+
+		if !bytes.Equal(body, want) {
+			offset := 0
+			for offset < len(body) && offset < len(want) && body[offset] == want[offset] {
+				offset++
+			}
+			diffs = append(diffs, fmt.Sprintf("body: differs at offset %d", offset))
+		}
+		gotBody, wantBody = string(body), string(want)
+	}
+
+	if len(diffs) == 0 {
+		return
+	}
+	t.Errorf("%s\n%s\n%s\n%s", c.Desc, strings.Join(diffs, "\n"), indent("got:  "+gotBody), indent("want: "+wantBody))
+}
+
+func statusString(code int) string {
+	if text := http.StatusText(code); text != "" {
+		return strconv.Itoa(code) + " " + text
+	}
+	return strconv.Itoa(code)
+}
+
+// headerString prints header values; a quoted string if single.
+func headerString(values []string) string {
+	if len(values) == 1 {
+		return strconv.Quote(values[0])
+	}
+	return fmt.Sprintf("%q", values)
+}
+
+// canonicalHeader merges values of h by canonicalized keys.
+func canonicalHeader(h http.Header) map[string][]string {
+	m := make(map[string][]string, len(h))
+	for k, v := range h {
+		k = http.CanonicalHeaderKey(k)
+		m[k] = append(m[k], v...)
+	}
+	return m
+}
@@ -0,0 +1,115 @@
+package gotwant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSON constructs a JSON-comparation test case.
+// got and want are JSON documents ([]byte, string, json.RawMessage or io.Reader),
+// or other values which are marshaled into JSON.
+//
+// Documents are compared semantically; key order and whitespaces are ignored.
+// Differences are reported by JSON path ($.items[0].name).
+func JSON(got, want interface{}, opts ...Option) *jsonCase {
+	c := &jsonCase{
+		Got:  got,
+		Want: want,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type jsonCase struct {
+	Got  interface{} // what you got.
+	Want interface{} // what you expected.
+
+	IgnorePaths []string // paths not compared. ($.a.b, $.items[*].id, ...)
+	AllowExtra  bool     // allow extra fields of objects in Got
+
+	Desc string // a line description
+}
+
+func (c *jsonCase) SetFmt(format string) {
+}
+
+func (c *jsonCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *jsonCase) SetIgnorePaths(paths []string) {
+	c.IgnorePaths = append(c.IgnorePaths, paths...)
+}
+
+func (c *jsonCase) SetAllowExtra(allow bool) {
+	c.AllowExtra = allow
+}
+
+func (c *jsonCase) Test(t T) {
+	t.Helper()
+
+	got, err := decodeJSON(c.Got)
+	if err != nil {
+		t.Errorf("%s\ngot invalid JSON: %v", c.Desc, err)
+		return
+	}
+	want, err := decodeJSON(c.Want)
+	if err != nil {
+		t.Errorf("%s\nwant invalid JSON: %v", c.Desc, err)
+		return
+	}
+
+	cmp := treeComparer{
+		ignore:     parsePaths(c.IgnorePaths),
+		allowExtra: c.AllowExtra,
+	}
+	cmp.test(t, c.Desc, got, want, canonicalJSON)
+}
+
+// decodeJSON decodes a JSON document into a tree of map[string]interface{}, []interface{} and scalars.
+// Numbers are json.Number.
+func decodeJSON(doc interface{}) (interface{}, error) {
+	var r io.Reader
+	switch d := doc.(type) {
+	case []byte:
+		r = bytes.NewReader(d)
+	case json.RawMessage:
+		r = bytes.NewReader(d)
+	case string:
+		r = strings.NewReader(d)
+	case io.Reader:
+		r = d
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return v, nil
+}
+
+// canonicalJSON prints v indented, with sorted keys.
+func canonicalJSON(v interface{}) string {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
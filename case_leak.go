@@ -0,0 +1,172 @@
+package gotwant
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// NoLeaks snapshots running goroutines, and tests no new ones remain at cleanup of t.
+// t must have Cleanup, such as *testing.T.
+//
+// Only goroutines started by the calling goroutine (the test), directly or via its descendants, are tested,
+// so that goroutines of other tests running in parallel (t.Parallel) are not reported.
+// A goroutine started via a goroutine which has already exited cannot be traced, and is not reported.
+//
+// Goroutines are given a grace period to exit. default: TimeoutDefault (Timeout option)
+func NoLeaks(t T, opts ...Option) {
+	t.Helper()
+
+	c := NoLeaksCase(opts...)
+
+	ct, ok := t.(interface{ Cleanup(func()) })
+	if !ok {
+		t.Errorf("%s\nNoLeaks: %T has no Cleanup", c.Desc, t)
+		return
+	}
+	ct.Cleanup(func() {
+		c.Test(t)
+	})
+}
+
+// NoLeaksCase constructs a test case of no new goroutines remaining, snapshotting running goroutines now.
+// As NoLeaks, only goroutines started by the calling goroutine are tested.
+func NoLeaksCase(opts ...Option) *leakCase {
+	c := &leakCase{
+		Timeout: TimeoutDefault,
+		known:   make(map[string]bool),
+		owner:   currentGoroutineID(),
+	}
+	for _, g := range goroutines() {
+		c.known[g.id] = true
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type leakCase struct {
+	Timeout time.Duration // a grace period for goroutines to exit.  default: TimeoutDefault
+	Desc    string        // a line description
+
+	known map[string]bool // IDs of goroutines at the snapshot
+	owner string          // ID of the goroutine which took the snapshot
+}
+
+func (c *leakCase) SetFmt(format string) {
+}
+
+func (c *leakCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *leakCase) SetTimeout(d time.Duration) {
+	c.Timeout = d
+}
+
+func (c *leakCase) Test(t T) {
+	t.Helper()
+
+	// descendants of the owner, kept while polling since creators may exit
+	ours := map[string]bool{c.owner: true}
+
+	deadline := time.Now().Add(c.Timeout)
+	var leaked []goroutine
+	for {
+		leaked = leaked[:0]
+		self := currentGoroutineID()
+		gs := goroutines()
+		for found := true; found; {
+			// gs are not in order of creation
+			found = false
+			for _, g := range gs {
+				if !ours[g.id] && ours[g.creator] {
+					ours[g.id] = true
+					found = true
+				}
+			}
+		}
+		for _, g := range gs {
+			if ours[g.id] && !c.known[g.id] && g.id != self && !g.isSystem() {
+				leaked = append(leaked, g)
+			}
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(leaked) == 0 {
+		return
+	}
+
+	stacks := make([]string, 0, len(leaked))
+	for _, g := range leaked {
+		stacks = append(stacks, indent("      "+g.stack))
+	}
+	t.Errorf("%s\n%d leaked goroutine(s)\n%s", c.Desc, len(leaked), strings.Join(stacks, "\n\n"))
+}
+
+// goroutine is a stack trace of a goroutine, from runtime.Stack.
+type goroutine struct {
+	id      string
+	creator string // ID of the goroutine which started it, or "" if unknown
+	stack   string
+}
+
+// goroutines returns stacks of all goroutines.
+func goroutines() []goroutine {
+	var buf []byte
+	for size := 64 << 10; ; size *= 2 {
+		buf = make([]byte, size)
+		if n := runtime.Stack(buf, true); n < size {
+			buf = buf[:n]
+			break
+		}
+	}
+
+	var gs []goroutine
+	for _, s := range strings.Split(strings.TrimSpace(string(buf)), "\n\n") {
+		g := goroutine{id: goroutineID(s), stack: s}
+		if m := creatorRE.FindStringSubmatch(s); m != nil {
+			g.creator = m[1]
+		}
+		gs = append(gs, g)
+	}
+	return gs
+}
+
+// creatorRE matches `created by main.f in goroutine 7`.
+var creatorRE = regexp.MustCompile(`\ncreated by \S+ in goroutine (\d+)`)
+
+func currentGoroutineID() string {
+	buf := make([]byte, 64)
+	return goroutineID(string(buf[:runtime.Stack(buf, false)]))
+}
+
+// goroutineID extracts an ID from `goroutine 18 [chan receive]:`.
+func goroutineID(stack string) string {
+	var id string
+	fmt.Sscanf(stack, "goroutine %s ", &id)
+	return id
+}
+
+// isSystem tells whether g is run by the runtime or the testing package.
+func (g goroutine) isSystem() bool {
+	for _, f := range []string{
+		"testing.tRunner(",
+		"testing.(*T).Run(",
+		"testing.RunTests(",
+		"testing.runFuzzing(",
+		"os/signal.signal_recv(",
+		"runtime.ensureSigM(",
+	} {
+		if strings.Contains(g.stack, "\n"+f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package gotwant
+
+import (
+	"reflect"
+)
+
+// Nil constructs a test case of got being nil.
+//
+// got is tested as its static type V.
+// A nil pointer is nil, but an interface (such as error) holding a nil pointer is not nil,
+// which is reported as "non-nil error holding nil *T".
+func Nil[V any](got V, opts ...Option) *nilCase {
+	c := &nilCase{
+		Got:  got,
+		Want: true,
+		typ:  reflect.TypeFor[V](),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// NotNil constructs a test case of got being not nil.
+//
+// got is tested as its static type V.
+// An interface (such as error) holding a nil pointer is also reported as "non-nil error holding nil *T",
+// as it is not nil but holds nothing.
+func NotNil[V any](got V, opts ...Option) *nilCase {
+	c := &nilCase{
+		Got:  got,
+		Want: false,
+		typ:  reflect.TypeFor[V](),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type nilCase struct {
+	Got  interface{} // what you got.
+	Want bool        // nil or not
+
+	Fmt  string // used in t.Errorf displaying got.  default: FmtDefault
+	Desc string // a line description
+
+	typ reflect.Type // the static type of Got
+
+	checker *Checker // set by Checker.Options
+}
+
+func (c *nilCase) SetFmt(format string) {
+	c.Fmt = format
+}
+
+func (c *nilCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *nilCase) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
+func (c *nilCase) Test(t T) {
+	t.Helper()
+
+	valfmt := c.Fmt
+	if valfmt == "" {
+		valfmt = c.checker.defaultFmt()
+	}
+
+	var isNil bool
+	if c.typ.Kind() == reflect.Interface {
+		if typ, ok := typedNil(c.Got); ok {
+			t.Errorf("%s\ngot non-nil %s holding nil %s.", c.Desc, typeString(c.typ), typeString(typ))
+			return
+		}
+		isNil = c.Got == nil
+	} else {
+		isNil = isNilValue(reflect.ValueOf(c.Got))
+	}
+
+	if c.Want && !isNil {
+		t.Errorf("%s\n%s\n%s", c.Desc, indent("got:  "+c.checker.sprint(valfmt, c.Got)), indent("want: nil"))
+	} else if !c.Want && isNil {
+		t.Errorf("%s\ngot nil %s.", c.Desc, typeString(c.typ))
+	}
+}
+
+// typedNil tells whether v is an interface holding a nil pointer (map, slice, ...), and returns its type.
+func typedNil(v interface{}) (reflect.Type, bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(v)
+	if isNilValue(rv) {
+		return rv.Type(), true
+	}
+	return nil, false
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,181 @@
+package gotwant
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// orderOp is a kind of ordering comparation.
+type orderOp int
+
+const (
+	opGreater orderOp = iota
+	opLess
+	opBetween
+)
+
+// Greater constructs a test case of got > bound.
+func Greater[N cmp.Ordered](got, bound N, opts ...Option) *orderCase[N] {
+	return newOrderCase(opGreater, got, bound, bound, opts)
+}
+
+// Less constructs a test case of got < bound.
+func Less[N cmp.Ordered](got, bound N, opts ...Option) *orderCase[N] {
+	return newOrderCase(opLess, got, bound, bound, opts)
+}
+
+// Between constructs a test case of low <= got <= high.
+func Between[N cmp.Ordered](got, low, high N, opts ...Option) *orderCase[N] {
+	return newOrderCase(opBetween, got, low, high, opts)
+}
+
+func newOrderCase[N cmp.Ordered](op orderOp, got, low, high N, opts []Option) *orderCase[N] {
+	c := &orderCase[N]{
+		Got:  got,
+		Low:  low,
+		High: high,
+		op:   op,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type orderCase[N cmp.Ordered] struct {
+	Got  N // what you got.
+	Low  N // the lower bound. (Greater, Between)
+	High N // the upper bound. (Less, Between)
+
+	Fmt  string // used in t.Errorf displaying got and bounds.  default: FmtDefault
+	Desc string // a line description
+
+	op orderOp
+
+	checker *Checker // set by Checker.Options
+}
+
+func (c *orderCase[N]) SetFmt(format string) {
+	c.Fmt = format
+}
+
+func (c *orderCase[N]) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *orderCase[N]) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
+func (c *orderCase[N]) Test(t T) {
+	t.Helper()
+
+	valfmt := c.Fmt
+	if valfmt == "" {
+		valfmt = c.checker.defaultFmt()
+	}
+
+	var want string
+	switch c.op {
+	case opGreater:
+		if c.Got > c.Low {
+			return
+		}
+		want = "> " + c.checker.sprint(valfmt, c.Low)
+	case opLess:
+		if c.Got < c.High {
+			return
+		}
+		want = "< " + c.checker.sprint(valfmt, c.High)
+	case opBetween:
+		if c.Low <= c.Got && c.Got <= c.High {
+			return
+		}
+		want = fmt.Sprintf(">= %s && <= %s", c.checker.sprint(valfmt, c.Low), c.checker.sprint(valfmt, c.High))
+	}
+
+	t.Errorf("%s\n%s\n%s", c.Desc, indent("got:  "+c.checker.sprint(valfmt, c.Got)), indent("want: "+want))
+}
+
+// Sorted constructs a test case of got sorted by less.
+func Sorted[E any](got []E, less func(a, b E) bool, opts ...Option) *sortedCase[E] {
+	c := &sortedCase[E]{
+		Got:  got,
+		Less: less,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Monotonic constructs a test case of got being non-decreasing or non-increasing.
+func Monotonic[N cmp.Ordered](got []N, opts ...Option) *sortedCase[N] {
+	c := &sortedCase[N]{
+		Got:       got,
+		Less:      cmp.Less[N],
+		monotonic: true,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type sortedCase[E any] struct {
+	Got  []E               // what you got.
+	Less func(a, b E) bool // the order
+
+	Fmt  string // used in t.Errorf displaying got.  default: FmtDefault
+	Desc string // a line description
+
+	monotonic bool // in either direction
+
+	checker *Checker // set by Checker.Options
+}
+
+func (c *sortedCase[E]) SetFmt(format string) {
+	c.Fmt = format
+}
+
+func (c *sortedCase[E]) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *sortedCase[E]) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
+func (c *sortedCase[E]) Test(t T) {
+	t.Helper()
+
+	valfmt := c.Fmt
+	if valfmt == "" {
+		valfmt = c.checker.defaultFmt()
+	}
+
+	less := c.Less
+	what := "sorted"
+	if c.monotonic {
+		what = "monotonic"
+
+		// the direction is decided by the first unequal pair
+		for i := 0; i+1 < len(c.Got); i++ {
+			if c.Less(c.Got[i+1], c.Got[i]) {
+				less = func(a, b E) bool { return c.Less(b, a) }
+				break
+			}
+			if c.Less(c.Got[i], c.Got[i+1]) {
+				break
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(c.Got); i++ {
+		if less(c.Got[i+1], c.Got[i]) {
+			t.Errorf("%s\nnot %s at [%d], [%d]: %s, %s\n%s", c.Desc, what, i, i+1,
+				c.checker.sprint(valfmt, c.Got[i]), c.checker.sprint(valfmt, c.Got[i+1]), indent("got:  "+c.checker.sprint(valfmt, c.Got)))
+			return
+		}
+	}
+}
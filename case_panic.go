@@ -12,6 +12,8 @@ type panicCase struct {
 
 	Fmt  string // used in t.Errorf displaying got and want.  default: FmtDefault
 	Desc string // a line description
+
+	checker *Checker // set by Checker.Options
 }
 
 // Panic constructs a panic-occur test case.
@@ -34,12 +36,16 @@ func (c *panicCase) SetDesc(desc string) {
 	c.Desc = desc
 }
 
+func (c *panicCase) setChecker(ch *Checker) {
+	c.checker = ch
+}
+
 func (c *panicCase) Test(t T) {
 	t.Helper()
 
 	valfmt := c.Fmt
 	if valfmt == "" {
-		valfmt = FmtDefault
+		valfmt = c.checker.defaultFmt()
 	}
 
 	var gotErr interface{}
@@ -58,16 +64,26 @@ func (c *panicCase) Test(t T) {
 	}
 
 	if gotErr == nil {
-		errfmt := fmt.Sprintf("%s\ngot NO panic.\nwant error: %s", c.Desc, valfmt)
-		t.Errorf(errfmt, c.Want)
+		t.Errorf("%s\ngot NO panic.\nwant error: %s", c.Desc, c.checker.sprint(valfmt, c.Want))
+		return
+	}
+
+	if typ, ok := typedNil(gotErr); ok {
+		if reflect.DeepEqual(gotErr, c.Want) {
+			return
+		}
+		t.Errorf("%s\ngot panic with nil %s.\nwant error: %s", c.Desc, typeString(typ), c.checker.sprint(valfmt, c.Want))
 		return
 	}
 
 	wantErrMsg := stringify(c.Want)
 	if wantErrMsg != nil {
 		// compare message
-		gotErrMsg := stringify(gotErr) // not nil
-		if strings.Contains(strings.ToLower(*gotErrMsg), strings.ToLower(*wantErrMsg)) {
+		gotErrMsg := fmt.Sprint(gotErr) // neither an error, a string nor a Stringer
+		if msg := stringify(gotErr); msg != nil {
+			gotErrMsg = *msg
+		}
+		if strings.Contains(strings.ToLower(gotErrMsg), strings.ToLower(*wantErrMsg)) {
 			return
 		}
 
@@ -77,6 +93,6 @@ func (c *panicCase) Test(t T) {
 		return
 	}
 
-	errfmt := fmt.Sprintf("%s\ngot error:  %s\nwant error: %s", c.Desc, valfmt, valfmt)
-	t.Errorf(errfmt, gotErr, c.Want)
+	gotS, wantS := c.checker.sprintPair(valfmt, gotErr, c.Want)
+	t.Errorf("%s\ngot error:  %s\nwant error: %s", c.Desc, gotS, wantS)
 }
@@ -0,0 +1,240 @@
+package gotwant
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shu-go/gotwant/diff"
+)
+
+// Text constructs a text-comparation test case.
+// Texts are compared line by line, aligned by a line diff, and got and want are printed with line numbers.
+//
+// Options TrimTrailingSpace, NormalizeEOL, Dedent and IgnoreBlankLines normalize texts before comparison.
+func Text(got, want string, opts ...Option) *textCase {
+	c := &textCase{
+		Got:  got,
+		Want: want,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type textCase struct {
+	Got  string // what you got.
+	Want string // what you expected.
+
+	TrimTrailingSpace bool // trim trailing whitespaces of each line
+	NormalizeEOL      bool // treat CRLF and CR as LF
+	Dedent            bool // dedent Want written in a raw string literal
+	IgnoreBlankLines  bool // ignore lines of whitespaces
+
+	Desc string // a line description
+}
+
+// TrimTrailingSpace trims trailing whitespaces of each line in Text cases.
+func TrimTrailingSpace() Option {
+	return func(c TestCase) {
+		if tc, ok := c.(interface{ SetTrimTrailingSpace(bool) }); ok {
+			tc.SetTrimTrailingSpace(true)
+		}
+	}
+}
+
+// NormalizeEOL treats CRLF and CR as LF in Text cases.
+func NormalizeEOL() Option {
+	return func(c TestCase) {
+		if tc, ok := c.(interface{ SetNormalizeEOL(bool) }); ok {
+			tc.SetNormalizeEOL(true)
+		}
+	}
+}
+
+// Dedent removes common indentation of want in Text cases,
+// as well as the first line break and whitespaces after the last line break, which surround a raw string literal.
+func Dedent() Option {
+	return func(c TestCase) {
+		if tc, ok := c.(interface{ SetDedent(bool) }); ok {
+			tc.SetDedent(true)
+		}
+	}
+}
+
+// IgnoreBlankLines ignores lines of whitespaces in Text cases.
+func IgnoreBlankLines() Option {
+	return func(c TestCase) {
+		if tc, ok := c.(interface{ SetIgnoreBlankLines(bool) }); ok {
+			tc.SetIgnoreBlankLines(true)
+		}
+	}
+}
+
+func (c *textCase) SetFmt(format string) {
+}
+
+func (c *textCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *textCase) SetTrimTrailingSpace(trim bool) {
+	c.TrimTrailingSpace = trim
+}
+
+func (c *textCase) SetNormalizeEOL(normalize bool) {
+	c.NormalizeEOL = normalize
+}
+
+func (c *textCase) SetDedent(dedent bool) {
+	c.Dedent = dedent
+}
+
+func (c *textCase) SetIgnoreBlankLines(ignore bool) {
+	c.IgnoreBlankLines = ignore
+}
+
+func (c *textCase) Test(t T) {
+	t.Helper()
+
+	want := c.Want
+	if c.Dedent {
+		want = dedent(want)
+	}
+	gotLines, wantLines := c.lines(c.Got), c.lines(want)
+
+	diffs := diffLines(gotLines, wantLines)
+	if len(diffs) == 0 {
+		return
+	}
+
+	t.Errorf("%s\n%s\n%s\n%s", c.Desc, strings.Join(diffs, "\n"),
+		indent("got:  "+numberLines(gotLines)), indent("want: "+numberLines(wantLines)))
+}
+
+// diffLines aligns lines by a line diff, and reports changed, unexpected and missing lines.
+func diffLines(gotLines, wantLines []textLine) []string {
+	join := func(lines []textLine) string {
+		sb := &strings.Builder{}
+		for _, l := range lines {
+			sb.WriteString(l.text + "\n")
+		}
+		return sb.String()
+	}
+
+	var results []string
+	var gi, wi int
+	var dels, inss []textLine // a block of edits between equalities
+	flush := func() {
+		for i := 0; i < len(dels) || i < len(inss); i++ {
+			switch {
+			case i >= len(dels):
+				w := inss[i]
+				results = append(results, fmt.Sprintf("want line %d: missing %s", w.num, strconv.Quote(w.text)))
+			case i >= len(inss):
+				g := dels[i]
+				results = append(results, fmt.Sprintf("line %d: unexpected %s", g.num, strconv.Quote(g.text)))
+			default:
+				g, w := dels[i], inss[i]
+				loc := fmt.Sprintf("line %d", g.num)
+				if g.num != w.num {
+					loc += fmt.Sprintf(" (want line %d)", w.num)
+				}
+				results = append(results, fmt.Sprintf("%s: got %s, want %s", loc, strconv.Quote(g.text), strconv.Quote(w.text)))
+			}
+		}
+		dels, inss = nil, nil
+	}
+
+	for _, d := range diff.Compute(join(gotLines), join(wantLines), diff.Line) {
+		n := strings.Count(d.Text, "\n")
+		switch d.Type {
+		case diff.Equal:
+			flush()
+			gi += n
+			wi += n
+		case diff.Delete:
+			dels = append(dels, gotLines[gi:gi+n]...)
+			gi += n
+		case diff.Insert:
+			inss = append(inss, wantLines[wi:wi+n]...)
+			wi += n
+		}
+	}
+	flush()
+
+	return results
+}
+
+// textLine is a line with its line number (1-based) in the original text.
+type textLine struct {
+	num  int
+	text string
+}
+
+// lines normalizes s and splits it into lines.
+func (c *textCase) lines(s string) []textLine {
+	if c.NormalizeEOL {
+		s = diff.NormalizeEOL(s)
+	}
+
+	var lines []textLine
+	for i, l := range strings.Split(s, "\n") {
+		if c.TrimTrailingSpace {
+			l = strings.TrimRight(l, " \t\r")
+		}
+		if c.IgnoreBlankLines && strings.TrimSpace(l) == "" {
+			continue
+		}
+		lines = append(lines, textLine{num: i + 1, text: l})
+	}
+	return lines
+}
+
+// numberLines prints lines with right-aligned line numbers.
+func numberLines(lines []textLine) string {
+	if len(lines) == 0 {
+		return "(empty)"
+	}
+
+	width := len(strconv.Itoa(lines[len(lines)-1].num))
+	sb := &strings.Builder{}
+	for i, l := range lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(sb, "%*d| %s", width, l.num, l.text)
+	}
+	return sb.String()
+}
+
+// dedent removes the first line break, whitespaces after the last line break and common indentation of s.
+func dedent(s string) string {
+	s = strings.TrimPrefix(s, "\n")
+	if i := strings.LastIndex(s, "\n"); i != -1 && strings.TrimSpace(s[i:]) == "" {
+		s = s[:i+1]
+	}
+
+	lines := strings.Split(s, "\n")
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		ind := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix, first = ind, false
+			continue
+		}
+		for !strings.HasPrefix(ind, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(l, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,195 @@
+package gotwant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// IsType constructs a test case of got having the same dynamic type as want, such as (*MyErr)(nil).
+func IsType(got, want interface{}, opts ...Option) *typeCase {
+	c := &typeCase{
+		Got:  got,
+		Want: reflect.TypeOf(want),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Implements constructs a test case of got implementing an interface.
+// iface is a pointer to the interface, such as (*io.Closer)(nil).
+func Implements(got, iface interface{}, opts ...Option) *typeCase {
+	c := &typeCase{
+		Got:        got,
+		Want:       reflect.TypeOf(iface),
+		implements: true,
+	}
+	if c.Want != nil && c.Want.Kind() == reflect.Pointer && c.Want.Elem().Kind() == reflect.Interface {
+		c.Want = c.Want.Elem()
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// AsType constructs a test case of got being a V.
+// If got is an error, errors wrapped by got are also tried (errors.As).
+func AsType[V any](got interface{}, opts ...Option) *typeCase {
+	c := &typeCase{
+		Got:  got,
+		Want: reflect.TypeFor[V](),
+		as:   true,
+	}
+	if c.Want.Kind() == reflect.Interface {
+		c.implements = true
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type typeCase struct {
+	Got  interface{}  // what you got.
+	Want reflect.Type // the type (or the interface) you expected.
+
+	Desc string // a line description
+
+	implements bool // Want is an interface
+	as         bool // try errors.As
+}
+
+func (c *typeCase) SetFmt(format string) {
+}
+
+func (c *typeCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *typeCase) Test(t T) {
+	t.Helper()
+
+	got := reflect.TypeOf(c.Got)
+
+	if c.as && asErrorTarget(c.Want) {
+		if err, ok := c.Got.(error); ok {
+			target := reflect.New(c.Want)
+			if errors.As(err, target.Interface()) {
+				return
+			}
+		}
+	}
+
+	if c.implements {
+		if c.Want == nil || c.Want.Kind() != reflect.Interface {
+			t.Errorf("%s\nwant %s, not an interface", c.Desc, typeString(c.Want))
+			return
+		}
+		if got != nil && got.Implements(c.Want) {
+			return
+		}
+		t.Errorf("%s\ngot type %s does not implement %s%s", c.Desc, typeString(got), typeString(c.Want), whyNotImplements(got, c.Want))
+		return
+	}
+
+	if got == c.Want {
+		return
+	}
+	t.Errorf("%s\ngot type:  %s\nwant type: %s", c.Desc, typeString(got), typeString(c.Want))
+}
+
+// asType converts got into a V, as AsType tests.
+func asType[V any](got interface{}) (V, bool) {
+	if v, ok := got.(V); ok {
+		return v, true
+	}
+
+	var v V
+	if err, ok := got.(error); ok && asErrorTarget(reflect.TypeFor[V]()) && errors.As(err, &v) {
+		return v, true
+	}
+	return v, false
+}
+
+// asErrorTarget tells whether a pointer to typ is acceptable as a target of errors.As.
+func asErrorTarget(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Interface || typ.Implements(reflect.TypeFor[error]())
+}
+
+// whyNotImplements explains why typ does not implement iface, like the compiler does.
+func whyNotImplements(typ, iface reflect.Type) string {
+	if typ == nil {
+		return ""
+	}
+
+	for i := 0; i < iface.NumMethod(); i++ {
+		want := iface.Method(i)
+		got, found := typ.MethodByName(want.Name)
+		if !found {
+			if typ.Kind() != reflect.Pointer && typ.Kind() != reflect.Interface {
+				if _, found := reflect.PointerTo(typ).MethodByName(want.Name); found {
+					return fmt.Sprintf(" (method %s has pointer receiver)", want.Name)
+				}
+			}
+			return fmt.Sprintf(" (missing method %s)", want.Name)
+		}
+
+		gotType := got.Type
+		if typ.Kind() != reflect.Interface {
+			// drop the receiver
+			in := make([]reflect.Type, gotType.NumIn()-1)
+			for j := range in {
+				in[j] = gotType.In(j + 1)
+			}
+			out := make([]reflect.Type, gotType.NumOut())
+			for j := range out {
+				out[j] = gotType.Out(j)
+			}
+			gotType = reflect.FuncOf(in, out, gotType.IsVariadic())
+		}
+		if gotType != want.Type {
+			return fmt.Sprintf(" (wrong type for method %s: got %s, want %s)", want.Name, typeString(gotType), typeString(want.Type))
+		}
+	}
+	return ""
+}
+
+// typeString prints typ with package paths, such as *github.com/shu-go/gotwant.cmpCase.
+func typeString(typ reflect.Type) string {
+	if typ == nil {
+		return "nil"
+	}
+
+	if typ.Name() != "" {
+		if typ.PkgPath() == "" {
+			return typ.Name()
+		}
+		return typ.PkgPath() + "." + typ.Name()
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return "*" + typeString(typ.Elem())
+	case reflect.Slice:
+		return "[]" + typeString(typ.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(typ.Len()) + "]" + typeString(typ.Elem())
+	case reflect.Map:
+		return "map[" + typeString(typ.Key()) + "]" + typeString(typ.Elem())
+	case reflect.Chan:
+		switch typ.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + typeString(typ.Elem())
+		case reflect.SendDir:
+			return "chan<- " + typeString(typ.Elem())
+		default:
+			return "chan " + typeString(typ.Elem())
+		}
+	default:
+		return typ.String()
+	}
+}
@@ -0,0 +1,256 @@
+package gotwant
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// XML constructs a XML-comparation test case.
+// got and want are XML documents ([]byte, string or io.Reader), or other values which are marshaled into XML.
+//
+// Documents are compared semantically; attribute order, whitespaces around text, comments and
+// order of differently named elements are ignored.
+// Differences are reported by paths like $.root.item[1]["@id"]:
+// attributes are @name, text is #text, and child elements are always indexed (even if only one).
+// Elements in other namespaces are {namespace}name.
+func XML(got, want interface{}, opts ...Option) *xmlCase {
+	c := &xmlCase{
+		Got:  got,
+		Want: want,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type xmlCase struct {
+	Got  interface{} // what you got.
+	Want interface{} // what you expected.
+
+	IgnorePaths []string // paths not compared. ($.root.a, $.root.item[*]["@id"], ...)
+	AllowExtra  bool     // allow extra attributes and elements in Got
+
+	Desc string // a line description
+}
+
+func (c *xmlCase) SetFmt(format string) {
+}
+
+func (c *xmlCase) SetDesc(desc string) {
+	c.Desc = desc
+}
+
+func (c *xmlCase) SetIgnorePaths(paths []string) {
+	c.IgnorePaths = append(c.IgnorePaths, paths...)
+}
+
+func (c *xmlCase) SetAllowExtra(allow bool) {
+	c.AllowExtra = allow
+}
+
+func (c *xmlCase) Test(t T) {
+	t.Helper()
+
+	got, err := decodeXML(c.Got)
+	if err != nil {
+		t.Errorf("%s\ngot invalid XML: %v", c.Desc, err)
+		return
+	}
+	want, err := decodeXML(c.Want)
+	if err != nil {
+		t.Errorf("%s\nwant invalid XML: %v", c.Desc, err)
+		return
+	}
+
+	cmp := treeComparer{
+		ignore:     parsePaths(c.IgnorePaths),
+		allowExtra: c.AllowExtra,
+	}
+	cmp.compare(nil, got.tree(), want.tree())
+	if len(cmp.diffs) == 0 {
+		return
+	}
+
+	t.Errorf("%s\n%s\n%s\n%s", c.Desc, strings.Join(cmp.diffs, "\n"),
+		indent("got:  "+got.String()), indent("want: "+want.String()))
+}
+
+// xmlNode is an element.
+type xmlNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr // sorted, without namespace declarations
+	Text     string     // trimmed
+	Children []*xmlNode
+}
+
+// decodeXML decodes the root element of a XML document.
+func decodeXML(doc interface{}) (*xmlNode, error) {
+	var r io.Reader
+	switch d := doc.(type) {
+	case []byte:
+		r = bytes.NewReader(d)
+	case string:
+		r = strings.NewReader(d)
+	case io.Reader:
+		r = d
+	default:
+		b, err := xml.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	dec := xml.NewDecoder(r)
+
+	var root *xmlNode
+	var stack []*xmlNode
+	var texts []*strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{Name: tok.Name}
+			for _, a := range tok.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Space == "" && a.Name.Local == "xmlns" {
+					continue
+				}
+				n.Attrs = append(n.Attrs, a)
+			}
+			sort.Slice(n.Attrs, func(i, j int) bool {
+				return xmlAttrKey(n.Attrs[i].Name) < xmlAttrKey(n.Attrs[j].Name)
+			})
+
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("multiple root elements")
+				}
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, n)
+			}
+			stack = append(stack, n)
+			texts = append(texts, &strings.Builder{})
+
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			n.Text = strings.TrimSpace(texts[len(texts)-1].String())
+			stack = stack[:len(stack)-1]
+			texts = texts[:len(texts)-1]
+
+		case xml.CharData:
+			if len(texts) != 0 {
+				texts[len(texts)-1].Write(tok)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// tree converts n into a tree for treeComparer, with the root element as the only field.
+//
+// An element is an object of attributes (@name), text (#text) and child elements,
+// or a string if it has text only.
+// Child elements are always an array, even if there is only one, since elements may repeat.
+// Elements in a namespace other than their parent's are keyed as {namespace}name.
+func (n *xmlNode) tree() interface{} {
+	return map[string]interface{}{
+		xmlElemKey(n.Name, ""): n.value(),
+	}
+}
+
+func (n *xmlNode) value() interface{} {
+	m := make(map[string]interface{})
+	for _, a := range n.Attrs {
+		m["@"+xmlAttrKey(a.Name)] = a.Value
+	}
+	if len(m) == 0 && len(n.Children) == 0 {
+		return n.Text
+	}
+	if n.Text != "" {
+		m["#text"] = n.Text
+	}
+
+	for _, c := range n.Children {
+		key := xmlElemKey(c.Name, n.Name.Space)
+		elems, _ := m[key].([]interface{})
+		m[key] = append(elems, c.value())
+	}
+	return m
+}
+
+// String prints n canonically; indented, with sorted attributes.
+func (n *xmlNode) String() string {
+	sb := &strings.Builder{}
+	n.write(sb, "", 0)
+	return sb.String()
+}
+
+func (n *xmlNode) write(sb *strings.Builder, parentSpace string, depth int) {
+	if depth > 0 {
+		sb.WriteString("\n" + strings.Repeat("  ", depth))
+	}
+
+	sb.WriteString("<" + n.Name.Local)
+	if n.Name.Space != parentSpace {
+		fmt.Fprintf(sb, " xmlns=%q", n.Name.Space)
+	}
+	for _, a := range n.Attrs {
+		sb.WriteString(" " + xmlAttrKey(a.Name) + `="`)
+		xml.EscapeText(sb, []byte(a.Value))
+		sb.WriteString(`"`)
+	}
+
+	if n.Text == "" && len(n.Children) == 0 {
+		sb.WriteString("/>")
+		return
+	}
+	sb.WriteString(">")
+
+	if len(n.Children) == 0 {
+		xml.EscapeText(sb, []byte(n.Text))
+	} else {
+		if n.Text != "" {
+			sb.WriteString("\n" + strings.Repeat("  ", depth+1))
+			xml.EscapeText(sb, []byte(n.Text))
+		}
+		for _, c := range n.Children {
+			c.write(sb, n.Name.Space, depth+1)
+		}
+		sb.WriteString("\n" + strings.Repeat("  ", depth))
+	}
+	sb.WriteString("</" + n.Name.Local + ">")
+}
+
+// xmlElemKey is a name of an element, qualified by its namespace if it differs from its parent's.
+func xmlElemKey(name xml.Name, parentSpace string) string {
+	if name.Space == parentSpace {
+		return name.Local
+	}
+	return xmlAttrKey(name)
+}
+
+// xmlAttrKey is a name of an attribute, qualified by its namespace if any.
+func xmlAttrKey(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
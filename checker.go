@@ -0,0 +1,198 @@
+package gotwant
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+	"unicode/utf8"
+)
+
+// Checker is a set of configurations of test cases, to be scoped per package or per test
+// instead of mutating FmtDefault and others.
+//
+// Package-level functions (Test, TestError, TestJSON, ...) work as Default.
+// Cases not having methods of Checker are configured by Options:
+//
+//	gotwant.TestText(t, got, want, checker.Options(gotwant.Dedent())...)
+//
+// A Checker must not be modified while it is used by tests.
+//
+// MaxLen is honored by value, expression, error, panic, nil, collection, order and channel cases.
+// Others (JSON, Text, Bytes, ...) print their own differences.
+type Checker struct {
+	Fmt     string        // a default format.  "": FmtDefault
+	Diff    bool          // append a diff of got and want.  false: DiffDefault
+	MaxLen  int           // max length of printed got and want.  0: unlimited
+	Timeout time.Duration // how long channel cases wait.  0: TimeoutDefault
+	Update  bool          // the update mode of Dir cases.  false: UpdateDefault
+
+	comparers map[reflect.Type]func(got, want interface{}) bool
+}
+
+// Default is a Checker used by package-level functions.
+// It is empty, so FmtDefault and others are read on every call.
+// Configure it (e.g. in TestMain) before tests run, for a max length, comparers and so on.
+var Default = &Checker{}
+
+// NewChecker makes a Checker of FmtDefault, DiffDefault, TimeoutDefault and UpdateDefault.
+func NewChecker() *Checker {
+	return &Checker{
+		Fmt:     FmtDefault,
+		Diff:    DiffDefault,
+		Timeout: TimeoutDefault,
+		Update:  UpdateDefault,
+	}
+}
+
+// RegisterComparer registers equal, used by ch instead of reflect.DeepEqual when got and want are V.
+// V must not be an interface type, since got and want are looked up by their dynamic types.
+func RegisterComparer[V any](ch *Checker, equal func(got, want V) bool) {
+	typ := reflect.TypeFor[V]()
+	if typ.Kind() == reflect.Interface {
+		panic("gotwant: RegisterComparer of an interface type " + typ.String())
+	}
+
+	if ch.comparers == nil {
+		ch.comparers = make(map[reflect.Type]func(got, want interface{}) bool)
+	}
+	ch.comparers[typ] = func(got, want interface{}) bool {
+		return equal(got.(V), want.(V))
+	}
+}
+
+// Options returns options configuring a case as ch, followed by opts.
+func (ch *Checker) Options(opts ...Option) []Option {
+	results := []Option{
+		func(c TestCase) {
+			if cc, ok := c.(interface{ setChecker(*Checker) }); ok {
+				cc.setChecker(ch)
+			} else if ch.Fmt != "" {
+				c.SetFmt(ch.Fmt)
+			}
+		},
+	}
+	if ch.Diff {
+		results = append(results, Diff(true))
+	}
+	if ch.Update {
+		results = append(results, Update(true))
+	}
+	if ch.Timeout > 0 {
+		results = append(results, Timeout(ch.Timeout))
+	}
+	return append(results, opts...)
+}
+
+// Case constructs a value-comaration test case.
+func (ch *Checker) Case(got, want interface{}, opts ...Option) *cmpCase {
+	return Case(got, want, ch.Options(opts...)...)
+}
+
+// ExprCase constructs a test case of given expr.
+func (ch *Checker) ExprCase(got interface{}, expr bool, opts ...Option) *exprCase {
+	return ExprCase(got, expr, ch.Options(opts...)...)
+}
+
+// Error constructs a error-comaration(nil, string) test case.
+func (ch *Checker) Error(got error, want interface{}, opts ...Option) *errCase {
+	return Error(got, want, ch.Options(opts...)...)
+}
+
+// Panic constructs a panic-occur test case.
+func (ch *Checker) Panic(got func(), want interface{}, opts ...Option) *panicCase {
+	return Panic(got, want, ch.Options(opts...)...)
+}
+
+// Test if for a single try.
+func (ch *Checker) Test(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	ch.Case(got, want, opts...).Test(t)
+}
+
+// TestExpr tests got == expr (boolean comparison)
+func (ch *Checker) TestExpr(t T, got interface{}, expr bool, opts ...Option) {
+	t.Helper()
+
+	ch.ExprCase(got, expr, opts...).Test(t)
+}
+
+// TestError tests given error (got) is (1) exactly the error you wanted or (2) its message matches your pattern.
+func (ch *Checker) TestError(t T, got error, want interface{}, opts ...Option) {
+	t.Helper()
+
+	ch.Error(got, want, opts...).Test(t)
+}
+
+// TestPanic tests function got panic(want) or not.
+func (ch *Checker) TestPanic(t T, got func(), want interface{}, opts ...Option) {
+	t.Helper()
+
+	ch.Panic(got, want, opts...).Test(t)
+}
+
+// defaultFmt returns a format used if a case has no Fmt. ch may be nil.
+func (ch *Checker) defaultFmt() string {
+	if ch == nil || ch.Fmt == "" {
+		return FmtDefault
+	}
+	return ch.Fmt
+}
+
+// comparer returns a comparer registered for got and want. ch may be nil.
+func (ch *Checker) comparer(got, want interface{}) (func(got, want interface{}) bool, bool) {
+	if ch == nil || got == nil || want == nil || reflect.TypeOf(got) != reflect.TypeOf(want) {
+		return nil, false
+	}
+	equal, found := ch.comparers[reflect.TypeOf(got)]
+	return equal, found
+}
+
+// sprint formats v, and truncates it. ch may be nil.
+func (ch *Checker) sprint(format string, v interface{}) string {
+	return ch.truncate(sprint(format, v))
+}
+
+// sprintPair formats got and want, and truncates them around their first difference. ch may be nil.
+func (ch *Checker) sprintPair(format string, got, want interface{}) (string, string) {
+	return ch.truncatePair(sprint(format, got), sprint(format, want))
+}
+
+// truncate cuts s longer than MaxLen. ch may be nil.
+func (ch *Checker) truncate(s string) string {
+	return ch.truncateAt(s, 0)
+}
+
+// truncatePair cuts gotS and wantS longer than MaxLen around their first difference. ch may be nil.
+func (ch *Checker) truncatePair(gotS, wantS string) (string, string) {
+	at := 0
+	for at < len(gotS) && at < len(wantS) && gotS[at] == wantS[at] {
+		at++
+	}
+	return ch.truncateAt(gotS, at), ch.truncateAt(wantS, at)
+}
+
+// truncateAt cuts s longer than MaxLen, keeping around s[at]. ch may be nil.
+func (ch *Checker) truncateAt(s string, at int) string {
+	if ch == nil || ch.MaxLen <= 0 || len(s) <= ch.MaxLen {
+		return s
+	}
+
+	start := max(at-ch.MaxLen/2, 0)
+	for start > 0 && !utf8.RuneStart(s[start]) {
+		start--
+	}
+	end := min(start+ch.MaxLen, len(s))
+	for end < len(s) && end > start && !utf8.RuneStart(s[end]) {
+		end--
+	}
+
+	result := s[start:end]
+	if start > 0 {
+		result = fmt.Sprintf("(%d more bytes) ...", start) + result
+	}
+	if end < len(s) {
+		result += fmt.Sprintf("... (%d more bytes)", len(s)-end)
+	}
+	return result
+}
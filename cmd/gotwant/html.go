@@ -0,0 +1,97 @@
+package main
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/shu-go/gotwant/diff"
+)
+
+// writeHTML writes rep as a standalone HTML page.
+func (c globalCmd) writeHTML(w io.Writer, rep *report) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"diff": func(got, want string) []template.HTML {
+			g, w := c.htmlDiff(got, want)
+			return []template.HTML{g, w}
+		},
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, rep)
+}
+
+// htmlDiff renders got (with deletions) and want (with insertions).
+func (c globalCmd) htmlDiff(got, want string) (gotHTML, wantHTML template.HTML) {
+	gotDiffs, wantDiffs := c.diffMain(got, want)
+
+	render := func(diffs []diff.Diff, skip diff.Operation) template.HTML {
+		return template.HTML(diff.RenderString(diff.HTMLRenderer{}, diff.Filter(diffs, skip)))
+	}
+
+	return render(gotDiffs, diff.Insert), render(wantDiffs, diff.Delete)
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gotwant report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { margin: 0.2em 0 0.5em 2em; padding: 0.3em; background: #f6f8fa; white-space: pre-wrap; }
+summary { cursor: pointer; }
+.totals span { margin-right: 1em; }
+.PASS, .ok { color: #1a7f37; }
+.FAIL { color: #cf222e; }
+.SKIP, .\? { color: #9a6700; }
+.test { margin-left: 1em; }
+.failure { margin: 0.5em 0 1em 1em; }
+.location { font-family: monospace; font-weight: bold; }
+.label { font-family: monospace; color: #57606a; }
+del { background: #ffebe9; color: #cf222e; text-decoration: none; font-weight: bold; }
+ins { background: #dafbe1; color: #1a7f37; text-decoration: none; font-weight: bold; }
+del.space, ins.space { text-decoration: underline; }
+</style>
+</head>
+<body>
+<h1>gotwant report</h1>
+<p class="totals">
+<span class="FAIL">failed: {{.Failed}}</span>
+<span class="PASS">passed: {{.Passed}}</span>
+<span class="SKIP">skipped: {{.Skipped}}</span>
+</p>
+{{- range .Packages}}
+<details{{if ne .Status "ok"}} open{{end}}>
+<summary><span class="{{.Status}}">{{.Status}}</span> {{.Name}} {{.Elapsed}}</summary>
+{{- range .Tests}}
+<details class="test"{{if eq .Status "FAIL"}} open{{end}}>
+<summary><span class="{{.Status}}">{{or .Status "----"}}</span> {{.Name}} ({{printf "%.2f" .Elapsed}}s)</summary>
+{{- range .Failures}}
+<div class="failure">
+<div class="location">{{.Location}}</div>
+{{- if .Desc}}
+<pre>{{.Desc}}</pre>
+{{- end}}
+{{- if .HasGW}}
+{{- $diff := diff .Got .Want}}
+<div class="label">got:</div>
+<pre>{{index $diff 0}}</pre>
+<div class="label">want:</div>
+<pre>{{index $diff 1}}</pre>
+{{- end}}
+</div>
+{{- else}}
+{{- if .Output}}
+<pre>{{range .Output}}{{.}}
+{{end}}</pre>
+{{- end}}
+{{- end}}
+</details>
+{{- end}}
+</details>
+{{- end}}
+</body>
+</html>
+`
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	rep, err := parseReport(strings.NewReader(testLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := globalCmd{Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.writeHTML(buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		// totals
+		`<span class="FAIL">failed: 2</span>`,
+		`<span class="PASS">passed: 2</span>`,
+		`<span class="SKIP">skipped: 1</span>`,
+		// passing tests and packages are collapsed, failing ones are open
+		`<details class="test">` + "\n" + `<summary><span class="PASS">PASS</span> TestA (0.01s)</summary>`,
+		`<details class="test" open>` + "\n" + `<summary><span class="FAIL">FAIL</span> TestB/sub (0.01s)</summary>`,
+		`<details>` + "\n" + `<summary><span class="ok">ok</span> example.com/bar 1.502s</summary>`,
+		// got with deletions, want with insertions
+		`<pre>a<del>b</del>c</pre>`,
+		`<pre>a<ins>x</ins>c</pre>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("%q not found in:\n%s", want, html)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/shu-go/gotwant/diff"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr,omitempty"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// writeJUnit writes rep as JUnit XML.
+// Each package is a testsuite, and each test is a testcase.
+func (c globalCmd) writeJUnit(w io.Writer, rep *report) error {
+	suites := junitTestSuites{}
+
+	for _, p := range rep.Packages {
+		suite := junitTestSuite{
+			Name: p.Name,
+			Time: strings.TrimSuffix(p.Elapsed, "s"),
+		}
+		if _, err := strconv.ParseFloat(suite.Time, 64); err != nil {
+			suite.Time = ""
+		}
+
+		for _, t := range p.Tests {
+			tc := junitTestCase{
+				Name:      t.Name,
+				ClassName: p.Name,
+				Time:      strconv.FormatFloat(t.Elapsed, 'f', 3, 64),
+			}
+
+			switch t.Status {
+			case "FAIL":
+				msgs := make([]string, 0, len(t.Failures))
+				for _, f := range t.Failures {
+					msgs = append(msgs, c.plainFailure(f))
+				}
+				if len(msgs) == 0 {
+					msgs = t.Output
+				}
+
+				message := "failed"
+				if len(t.Failures) != 0 {
+					message = t.Failures[0].Location
+					if t.Failures[0].Desc != "" {
+						message += ": " + firstLine(t.Failures[0].Desc)
+					}
+				} else if subs := failedSubtests(p.Tests, t.Name); len(subs) != 0 {
+					// a parent of failed subtests
+					message = "failed subtests: " + strings.Join(subs, ", ")
+					if len(msgs) == 0 {
+						msgs = subs
+					}
+				}
+
+				tc.Failure = &junitMessage{
+					Message: message,
+					Type:    "gotwant",
+					Text:    strings.Join(msgs, "\n\n"),
+				}
+				suite.Failures++
+			case "SKIP":
+				tc.Skipped = &junitMessage{
+					Message: strings.Join(t.Output, "\n"),
+				}
+				suite.Skipped++
+			default:
+				tc.SystemOut = strings.Join(t.Output, "\n")
+			}
+
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// plainFailure renders a failure as plain text, with got/want and their inline diff.
+func (c globalCmd) plainFailure(f *failure) string {
+	sb := &strings.Builder{}
+
+	sb.WriteString(f.Location)
+	sb.WriteString(":")
+	if f.Desc != "" {
+		sb.WriteString(" ")
+		sb.WriteString(f.Desc)
+	}
+	if f.HasGW {
+		const indent = "\n      "
+		fmt.Fprintf(sb, "\ngot:  %s", strings.ReplaceAll(f.Got, "\n", indent))
+		fmt.Fprintf(sb, "\nwant: %s", strings.ReplaceAll(f.Want, "\n", indent))
+		_, diffs := c.diffMain(f.Got, f.Want)
+		fmt.Fprintf(sb, "\ndiff: %s", strings.ReplaceAll(diff.PlainText(diffs), "\n", indent))
+	}
+
+	return sb.String()
+}
+
+// failedSubtests returns names of failed direct subtests of parent.
+func failedSubtests(tests []*testResult, parent string) []string {
+	var names []string
+	for _, t := range tests {
+		rest, found := strings.CutPrefix(t.Name, parent+"/")
+		if found && !strings.Contains(rest, "/") && t.Status == "FAIL" {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	rep, err := parseReport(strings.NewReader(testLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := globalCmd{Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.writeJUnit(buf, rep); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+	if suites.Tests != 5 || suites.Failures != 2 || suites.Skipped != 1 {
+		t.Errorf("tests=%d, failures=%d, skipped=%d", suites.Tests, suites.Failures, suites.Skipped)
+	}
+
+	// grouped by package
+	if len(suites.Suites) != 2 {
+		t.Fatalf("%d suites", len(suites.Suites))
+	}
+	foo, bar := suites.Suites[0], suites.Suites[1]
+	if foo.Name != "example.com/foo" || foo.Tests != 4 || foo.Failures != 2 || foo.Skipped != 1 || foo.Time != "0.031" {
+		t.Errorf("%+v", foo)
+	}
+	if bar.Name != "example.com/bar" || bar.Tests != 1 || bar.Failures != 0 || bar.Time != "1.502" {
+		t.Errorf("%+v", bar)
+	}
+
+	// durations of --- PASS/FAIL/SKIP lines
+	for i, want := range []string{"0.010", "0.020", "0.010", "0.000"} {
+		if got := foo.Cases[i].Time; got != want {
+			t.Errorf("%s: time=%s, want %s", foo.Cases[i].Name, got, want)
+		}
+	}
+	if got := bar.Cases[0].Time; got != "1.500" {
+		t.Errorf("%s: time=%s", bar.Cases[0].Name, got)
+	}
+
+	// a parent of a failed subtest
+	parent := foo.Cases[1]
+	if parent.Failure == nil || parent.Failure.Message != "failed subtests: TestB/sub" || parent.Failure.Text != "TestB/sub" {
+		t.Errorf("%s: %+v", parent.Name, parent.Failure)
+	}
+
+	sub := foo.Cases[2]
+	if sub.Failure == nil || sub.Failure.Message != "b_test.go:10" || !strings.Contains(sub.Failure.Text, "diff: a[-b-]{+x+}c") {
+		t.Errorf("%s: %+v", sub.Name, sub.Failure)
+	}
+
+	if skip := foo.Cases[3]; skip.Skipped == nil || skip.Skipped.Message != "c_test.go:5: not yet" {
+		t.Errorf("%s: %+v", skip.Name, skip.Skipped)
+	}
+}
@@ -7,26 +7,43 @@ import (
 	"io"
 	"os"
 	"regexp"
-	"slices"
 	"strings"
-	"unicode"
 
 	"github.com/fatih/color"
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant/diff"
 )
 
 type globalCmd struct {
 	Monochrome bool `cli:"m,mono,monochrome" default:"false"`
+	Both       bool `cli:"b,both" help:"colorize both got (deletions) and want (insertions), like most diff tools"`
+
+	Theme      string `cli:"theme" default:"default" env:"GOTWANT_THEME" help:"color theme (default, light, colorblind, okabe-ito, mono)"`
+	ThemeFile  string `cli:"theme-file" env:"GOTWANT_THEME_FILE" help:"a file of KEY=STYLE lines overriding the theme"`
+	Colors     string `cli:"colors" env:"GOTWANT_COLORS" help:"KEY:STYLE;... overriding the theme (e.g. insert:#0072b2,bold;delete:208)"`
+	ColorDepth string `cli:"color-depth" default:"auto" env:"GOTWANT_COLOR_DEPTH" help:"auto, 16, 256 or truecolor"`
+
+	Granularity string `cli:"g,granularity" default:"char" help:"unit of diffs: char, word or line"`
+
+	Visible     bool `cli:"w,visible" help:"render invisible characters visibly (space: ·, tab: →, CR: ␍, zero-width: <ZWSP>...)"`
+	IgnoreSpace bool `cli:"ignore-space" help:"ignore changes in the amount of whitespace and trailing whitespace"`
+	IgnoreEOL   bool `cli:"ignore-eol" help:"ignore differences of line endings (CRLF, CR, LF)"`
 
 	Efficiency       bool `cli:"e,efficiency" help:"reduces the number of edits by eliminating operationally trivial equalities"`
 	Merge            bool `cli:"merge" help:"Any edit section can move as long as it doesn't cross an equality"`
 	Semantic         bool `cli:"s,semantic" help:"reduces the number of edits by eliminating semantically trivial equalities"`
 	SemanticLossless bool `cli:"sl,semantic-lossless" help:"looks for single edits surrounded on both sides by equalities which can be shifted sideways to align the edit to a word boundary"`
 
+	HTML     string `cli:"html=FILE" help:"write an HTML report to FILE (- for stdout)"`
+	JUnit    string `cli:"junit=FILE" help:"write a JUnit XML report to FILE (- for stdout)"`
+	Markdown string `cli:"markdown=FILE" help:"write a Markdown summary of failures to FILE (- for stdout)"`
+	Append   bool   `cli:"append" help:"append the Markdown summary to FILE instead of overwriting (e.g. $GITHUB_STEP_SUMMARY)"`
+
 	Debug bool
 
 	debug func(string, ...interface{})
+	theme *theme
 }
 
 func Output(format string, a ...interface{}) {
@@ -42,26 +59,66 @@ const (
 	searchingGot state = iota
 	readingGot
 	readingWant
+	skippingDiff
 )
 
-func (c *globalCmd) Before() {
+func (c *globalCmd) Before() error {
 	if c.Debug {
 		c.debug = Output
 	} else {
 		c.debug = func(string, ...interface{}) {}
 	}
+
+	if forceColor() {
+		color.NoColor = false
+	}
+	if color.NoColor {
+		// NO_COLOR, TERM=dumb or not a terminal
+		c.Monochrome = true
+	}
+
+	if _, err := diff.ParseGranularity(c.Granularity); err != nil {
+		return err
+	}
+
+	depth, err := detectColorDepth(c.ColorDepth)
+	if err != nil {
+		return err
+	}
+	c.theme, err = loadTheme(c.Theme, c.ThemeFile, c.Colors, depth)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (c globalCmd) Run() error {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	toStdout, err := c.writeReports(input)
+	if err != nil {
+		return err
+	}
+	if toStdout {
+		return nil
+	}
+
+	return c.colorize(bytes.NewReader(input), os.Stdout)
+}
+
+// colorize copies a `go test` log from src to dst, coloring got-want parts.
+func (c globalCmd) colorize(src io.Reader, dst io.Writer) error {
 	buf := &bytes.Buffer{}
 
-	r := bufio.NewReader(os.Stdin)
+	r := bufio.NewReader(src)
 
 	var got, want string
 	gwIndent := 0
 
-	gwRE := regexp.MustCompile(`^(\s*)(got:|want:)\s( *)`)
-
 	s := searchingGot
 	for {
 		line, err := r.ReadString('\n')
@@ -73,6 +130,19 @@ func (c globalCmd) Run() error {
 		c.debug("*****")
 		c.debug("line=%q", line)
 
+		if s == skippingDiff {
+			if indent > gwIndent {
+				continue
+			}
+			s = searchingGot
+		}
+		// a diff line of gotwant.Diff is at the column of got and want, right after want
+		m := diffLineRE.FindStringSubmatch(line)
+		isLibDiff := s == readingWant && m != nil && len(m[1]) == gwIndent
+		// so is a reason line of gotwant.Test, explaining why printed-identical got and want are not DeepEqual
+		m = reasonRE.FindStringSubmatch(line)
+		isReason := s == readingWant && m != nil && len(m[1]) == gwIndent
+
 		matches := gwRE.FindStringSubmatch(line)
 		c.debug("matches=%#v", matches)
 		if len(matches) != 0 {
@@ -105,7 +175,7 @@ func (c globalCmd) Run() error {
 		if strings.HasPrefix(trimline, outputIndentStr) {
 			trimline = trimline[len(outputIndentStr):]
 		}
-		if !strings.HasPrefix(trimline, "FAIL") && !strings.HasPrefix(trimline, "---") && gwIndent <= indent {
+		if !strings.HasPrefix(trimline, "FAIL") && !strings.HasPrefix(trimline, "---") && !isLibDiff && !isReason && gwIndent <= indent {
 			if s == readingGot {
 				if got != "" {
 					got += "\n"
@@ -127,261 +197,147 @@ func (c globalCmd) Run() error {
 		// colorize
 		if s == readingWant {
 			c.debug("OUTPUT")
-			dmp := diffmatchpatch.New()
-			dmpdiffs := dmp.DiffMain(got, want, true)
-			c.debug("BEFORE")
-			for i, d := range dmpdiffs {
-				c.debug("%d dmpdiff=%v:%q", i, d.Type, d.Text)
-			}
-			switch true {
-			case c.Efficiency:
-				dmpdiffs = dmp.DiffCleanupEfficiency(dmpdiffs)
-			case c.Merge:
-				dmpdiffs = dmp.DiffCleanupMerge(dmpdiffs)
-			case c.Semantic:
-				dmpdiffs = dmp.DiffCleanupSemantic(dmpdiffs)
-			case c.SemanticLossless:
-				dmpdiffs = dmp.DiffCleanupSemanticLossless(dmpdiffs)
-			default:
-			}
-			if c.Efficiency || c.Merge || c.Semantic || c.SemanticLossless {
-				c.debug("AFTER CLEANUP")
-				for i, d := range dmpdiffs {
-					c.debug("%d dmpdiff=%v:%q", i, d.Type, d.Text)
-				}
-			}
-			dmpdiffs = splitByNewline(dmpdiffs)
-			dmpdiffs = addIndents(dmpdiffs, outputIndentStr)
-			c.debug("AFTER INDENTATION")
-			for i, d := range dmpdiffs {
-				c.debug("%d dmpdiff=%v:%q", i, d.Type, d.Text)
-			}
-			diffs := splitDiff(suppressPrefixUnderline(dmpdiffs))
-			c.debug("AFTER SUPPRESSION")
-			for i, d := range diffs {
-				c.debug("%d diff=%v:%q (%v)", i, d.Type, d.Text, d.isSpace)
-			}
-
+			gotDiffs, wantDiffs := c.diffMain(got, want)
 			buf.WriteString(strings.Repeat(" ", gwIndent))
 			buf.WriteString("got:  ")
-			buf.WriteString(strings.ReplaceAll(got, "\n", "\n"+outputIndentStr))
-			if !strings.HasSuffix(got, "\n") {
+			if c.Monochrome || !c.Both {
+				// what got is (made visible if specified)
+				gotText := diff.RenderString(plainRenderer{}, diff.Filter(gotDiffs, diff.Insert))
+				buf.WriteString(strings.ReplaceAll(gotText, "\n", "\n"+outputIndentStr))
+				if !strings.HasSuffix(gotText, "\n") {
+					buf.WriteByte('\n')
+				}
+			} else {
+				c.writeDiffs(buf, c.prepareDiffs(diff.Filter(gotDiffs, diff.Insert), outputIndentStr))
 				buf.WriteByte('\n')
 			}
 
 			buf.WriteString(strings.Repeat(" ", gwIndent))
 			buf.WriteString("want: ")
 			if c.Monochrome {
-				buf.WriteString(strings.ReplaceAll(want, "\n", "\n"+outputIndentStr))
+				wantText := diff.RenderString(plainRenderer{}, diff.Filter(wantDiffs, diff.Delete))
+				buf.WriteString(strings.ReplaceAll(wantText, "\n", "\n"+outputIndentStr))
 				buf.WriteByte('\n')
 			} else {
-				//buf.WriteString(dmp.DiffPrettyText(diffs))
-				minus := color.New(color.FgGreen, color.Bold)
-				minusS := color.New(color.FgGreen, color.Underline, color.Bold)
-				plus := color.New(color.FgRed, color.Bold)
-				plusS := color.New(color.FgRed, color.Underline, color.Bold)
-
-				for _, d := range diffs {
-					switch d.Type {
-					case diffmatchpatch.DiffEqual:
-						buf.WriteString(d.Text)
-					case diffmatchpatch.DiffDelete:
-						if d.isSpace {
-							plusS.Fprint(buf, d.Text)
-						} else {
-							plus.Fprint(buf, d.Text)
-						}
-					case diffmatchpatch.DiffInsert:
-						if d.isSpace {
-							minusS.Fprint(buf, d.Text)
-						} else {
-							minus.Fprint(buf, d.Text)
-						}
-					default:
-					}
+				if c.Both {
+					wantDiffs = diff.Filter(wantDiffs, diff.Delete)
 				}
+				c.writeDiffs(buf, c.prepareDiffs(wantDiffs, outputIndentStr))
 				buf.WriteByte('\n')
-
 			}
 		}
+		if s == readingWant && isLibDiff && !c.Monochrome {
+			// a plain-text diff (gotwant.Diff) is replaced by the colored one.
+			// in monochrome, it is kept since no colored one is written.
+			s = skippingDiff
+			continue
+		}
 		s = searchingGot
 
 		buf.WriteString(line)
 	}
 
-	io.Copy(os.Stdout, buf)
+	_, err := io.Copy(dst, buf)
 
-	return nil
-}
-
-type diff struct {
-	diffmatchpatch.Diff
-	isSpace bool
+	return err
 }
 
-func suppressPrefixUnderline(diffs []diffmatchpatch.Diff) []diffmatchpatch.Diff {
-	result := make([]diffmatchpatch.Diff, 0, len(diffs)*2)
-
-	result = slices.Insert(result, 0, diffs...)
-
-	suppressLen := 14
-	linePrefix := strings.Repeat(" ", suppressLen)
-
-	index := len(result) - 1
-	for index >= 0 {
-		if result[index].Type == diffmatchpatch.DiffEqual {
-			index--
-			continue
-		}
-
-		insIndex := index
-		for {
-			//fmt.Fprintf(os.Stderr, " [%d] %s %q\n", insIndex, result[insIndex].Type, result[insIndex].Text)
-			pos := strings.Index(result[insIndex].Text, linePrefix)
-			if pos == -1 || result[insIndex].Text == linePrefix {
-				break
-			}
-			//fmt.Fprintf(os.Stderr, " %d\n", pos)
-
-			d := result[insIndex]
-
-			newD := d
-			newD.Type = diffmatchpatch.DiffEqual
-			newD.Text = linePrefix
-			//fmt.Fprintf(os.Stderr, "  [%d(%d)] %s %q\n", insIndex, 0, newD.Type, newD.Text)
-			result[insIndex] = newD
-
-			back := 0
+// diffMain computes diffs between got and want, and cleans them up as specified.
+// Equalities are taken from got in gotDiffs and from want in wantDiffs (see diff.Denormalize).
+func (c globalCmd) diffMain(got, want string) (gotDiffs, wantDiffs []diff.Diff) {
+	// normalize copies, not to print normalized got and want
+	ngot, nwant := got, want
+	if c.IgnoreEOL {
+		ngot, nwant = diff.NormalizeEOL(ngot), diff.NormalizeEOL(nwant)
+	}
+	if c.IgnoreSpace {
+		ngot, nwant = diff.NormalizeSpace(ngot), diff.NormalizeSpace(nwant)
+	}
 
-			if pos+suppressLen < len(d.Text) {
-				newD.Type = d.Type
-				newD.Text = d.Text[pos+suppressLen:]
-				//fmt.Fprintf(os.Stderr, "  [%d(%d)] %s %q\n", insIndex+1, +1, newD.Type, newD.Text)
-				result = slices.Insert(result, insIndex+1, newD)
+	g := diff.Granularity(c.Granularity)
+	if isHexdump(got) && isHexdump(want) {
+		// hexdumps of gotwant.Bytes are compared byte by byte, not digit by digit
+		g = diff.Word
+	}
 
-				back++
-			}
-			//debug
-			//for i, d := range result {
-			//	fmt.Fprintf(os.Stderr, "  %d: %v:%q\n", i, d.Type, d.Text)
-			//}
-			//println("---")
-
-			if pos > 0 {
-				newD := d
-				newD.Text = d.Text[:pos]
-				//fmt.Fprintf(os.Stderr, "  [%d(%d)] %s %q\n", insIndex, -1, newD.Type, newD.Text)
-				result = slices.Insert(result, insIndex, newD)
-
-				back++
-			}
-			//debug
-			//for i, d := range result {
-			//	fmt.Fprintf(os.Stderr, "  %d: %v:%q\n", i, d.Type, d.Text)
-			//}
-			//println("---")
-
-			//println("  back", back, insIndex)
-			insIndex += back
-			//println(" >back", back, insIndex)
+	dmp := diffmatchpatch.New()
+	dmpdiffs := diff.ComputeWith(dmp, ngot, nwant, g)
+	c.debug("BEFORE")
+	for i, d := range dmpdiffs {
+		c.debug("%d dmpdiff=%v:%q", i, d.Type, d.Text)
+	}
+	switch true {
+	case c.Efficiency:
+		dmpdiffs = dmp.DiffCleanupEfficiency(dmpdiffs)
+	case c.Merge:
+		dmpdiffs = dmp.DiffCleanupMerge(dmpdiffs)
+	case c.Semantic:
+		dmpdiffs = dmp.DiffCleanupSemantic(dmpdiffs)
+	case c.SemanticLossless:
+		dmpdiffs = dmp.DiffCleanupSemanticLossless(dmpdiffs)
+	default:
+	}
+	if c.Efficiency || c.Merge || c.Semantic || c.SemanticLossless {
+		c.debug("AFTER CLEANUP")
+		for i, d := range dmpdiffs {
+			c.debug("%d dmpdiff=%v:%q", i, d.Type, d.Text)
 		}
-
-		index--
 	}
 
-	return result
+	gotDiffs, wantDiffs = dmpdiffs, dmpdiffs
+	if c.IgnoreEOL || c.IgnoreSpace {
+		gotDiffs, wantDiffs = diff.Denormalize(dmpdiffs, got, want, c.IgnoreEOL, c.IgnoreSpace)
+	}
+	if c.Visible {
+		gotDiffs, wantDiffs = diff.Visualize(gotDiffs), diff.Visualize(wantDiffs)
+	}
+	return gotDiffs, wantDiffs
 }
 
-func splitByNewline(diffs []diffmatchpatch.Diff) []diffmatchpatch.Diff {
-	results := make([]diffmatchpatch.Diff, 0, len(diffs))
-
-	for _, d := range diffs {
-		dText := d.Text
-		for {
-			pos := strings.Index(dText, "\n")
-			if pos == -1 {
-				break
-			}
-
-			newD := d
-			newD.Text = dText[:pos+1]
-			results = append(results, newD)
-
-			dText = dText[pos+1:]
-		}
-
-		if dText != "" {
-			newD := d
-			newD.Text = dText
-			results = append(results, newD)
-		}
+// prepareDiffs splits diffs by newline, indents continuation lines and suppresses decorations of the indents.
+func (c globalCmd) prepareDiffs(dmpdiffs []diff.Diff, outputIndentStr string) []diff.Diff {
+	dmpdiffs = diff.SplitByNewline(dmpdiffs)
+	dmpdiffs = diff.AddIndents(dmpdiffs, outputIndentStr)
+	c.debug("AFTER INDENTATION")
+	for i, d := range dmpdiffs {
+		c.debug("%d dmpdiff=%v:%q", i, d.Type, d.Text)
 	}
-
-	return results
+	return diff.SuppressPrefix(dmpdiffs, strings.Repeat(" ", 14))
 }
 
-func addIndents(diffs []diffmatchpatch.Diff, indent string) []diffmatchpatch.Diff {
-	newlined := false
+func (c globalCmd) writeDiffs(buf *bytes.Buffer, diffs []diff.Diff) {
+	r := diff.ANSIRenderer{
+		Insert:      c.theme.Insert,
+		InsertSpace: c.theme.InsertSpace,
+		Delete:      c.theme.Delete,
+		DeleteSpace: c.theme.DeleteSpace,
+	}
+	r.Render(buf, diffs) // bytes.Buffer never fails
+}
 
-	results := make([]diffmatchpatch.Diff, 0, len(diffs))
+// hexdumpRE matches a line of hexdumps printed by gotwant.Bytes.
+var hexdumpRE = regexp.MustCompile(`^[0-9a-f]{8}  ([0-9a-f]{2} |   ){8} ([0-9a-f]{2} |   ){8} \|.*\|$`)
 
-	for _, d := range diffs {
-		dText := d.Text
-		if newlined {
-			newD := diffmatchpatch.Diff{
-				Type: diffmatchpatch.DiffEqual,
-				Text: indent,
-			}
-			results = append(results, newD)
+// isHexdump tells whether all lines of s are hexdumps.
+func isHexdump(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if !hexdumpRE.MatchString(line) {
+			return false
 		}
-
-		results = append(results, d)
-
-		newlined = strings.Contains(dText, "\n")
 	}
-
-	return results
+	return true
 }
 
-func splitDiff(diffs []diffmatchpatch.Diff) []diff {
-	results := make([]diff, 0, len(diffs))
+// plainRenderer writes texts of diffs as they are.
+type plainRenderer struct{}
 
+func (plainRenderer) Render(w io.Writer, diffs []diff.Diff) error {
 	for _, d := range diffs {
-		if len(d.Text) == 0 {
-			continue
-		}
-
-		var prevSpace bool
-		var s []rune
-		for i, r := range d.Text {
-			space := !unicode.IsPrint(r) || unicode.IsSpace(r)
-			if i == 0 {
-				prevSpace = space
-			}
-
-			if space != prevSpace {
-				newD := d
-				newD.Text = string(s)
-				results = append(results, diff{
-					Diff:    newD,
-					isSpace: prevSpace,
-				})
-				s = s[:0]
-			}
-			s = append(s, r)
-			prevSpace = space
-		}
-		if len(s) > 0 {
-			newD := d
-			newD.Text = string(s)
-			results = append(results, diff{
-				Diff:    newD,
-				isSpace: prevSpace,
-			})
+		if _, err := io.WriteString(w, d.Text); err != nil {
+			return err
 		}
 	}
-
-	return results
+	return nil
 }
 
 func countIndent(line string) int {
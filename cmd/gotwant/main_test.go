@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/shu-go/gotwant/diff"
+)
+
+func TestColorizeLibDiff(t *testing.T) {
+	input := `--- FAIL: TestX (0.00s)
+    x_test.go:1:
+        got:  a
+              b
+        want: a
+              diff: c
+        diff: a [-b-]{+diff: c+}
+FAIL
+`
+	// monochrome: the diff line is kept, since no colored diff is written
+	c := globalCmd{Monochrome: true, Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.colorize(strings.NewReader(input), buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != input {
+		t.Errorf("\ngot:\n%s\nwant:\n%s", got, input)
+	}
+
+	// colored: the diff line is replaced by the colored diff (without escape sequences below)
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+	color.NoColor = false // as a terminal
+
+	c = globalCmd{Both: true, Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err := c.colorize(strings.NewReader(input), buf); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(input, "        diff: a [-b-]{+diff: c+}\n", "", 1)
+	if got := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(buf.String(), ""); got != want {
+		t.Errorf("\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	rep, err := parseReport(strings.NewReader("=== RUN   TestX\n" + input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := rep.Packages[0].Tests[0].Failures[0]
+	if f.Want != "a\ndiff: c" {
+		t.Errorf("want: %q", f.Want)
+	}
+}
+
+func TestDiffMainHexdump(t *testing.T) {
+	got := "000001f0  f0 f1 f2 f3 f4 f5 f6 f7  f8 f9 fa fb fc fd fe ff  |................|"
+	want := "000001f0  f0 f1 f2 f3 78 f5 f6 f7  f8 f9 fa fb fc fd fe ff  |....x...........|"
+
+	c := globalCmd{Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	// byte by byte, even in char granularity
+	_, wantDiffs := c.diffMain(got, want)
+	if plain := diff.PlainText(wantDiffs); plain != "000001f0  f0 f1 f2 f3 [-f4-]{+78+} f5 f6 f7  f8 f9 fa fb fc fd fe ff  |....[-.-]{+x+}...........|" {
+		t.Error(plain)
+	}
+}
+
+func TestColorizeBoth(t *testing.T) {
+	input := `--- FAIL: TestX (0.00s)
+    x_test.go:1:
+        got:  abc
+        want: axc
+FAIL
+`
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+	color.NoColor = false // as a terminal
+
+	// a fake theme: insertions in green, deletions in red
+	th := &theme{
+		Insert:      color.New(color.FgGreen),
+		InsertSpace: color.New(color.FgGreen),
+		Delete:      color.New(color.FgRed),
+		DeleteSpace: color.New(color.FgRed),
+	}
+	ins, del := th.Insert.Sprint("x"), th.Delete.Sprint("b")
+
+	tests := []struct {
+		both      bool
+		got, want string
+	}{
+		{both: true, got: "a" + del + "c", want: "a" + ins + "c"},
+		// want only
+		{both: false, got: "abc", want: "a" + del + ins + "c"},
+	}
+	for _, tc := range tests {
+		c := globalCmd{Both: tc.both, Granularity: "char"}
+		if err := c.Before(); err != nil {
+			t.Fatal(err)
+		}
+		c.theme = th
+
+		buf := &bytes.Buffer{}
+		if err := c.colorize(strings.NewReader(input), buf); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.NewReplacer("got:  abc", "got:  "+tc.got, "want: axc", "want: "+tc.want).Replace(input)
+		if got := buf.String(); got != want {
+			t.Errorf("both=%v:\ngot:\n%q\nwant:\n%q", tc.both, got, want)
+		}
+	}
+}
+
+func TestColorizeReason(t *testing.T) {
+	input := `--- FAIL: TestX (0.00s)
+    x_test.go:1:
+        got:  {a 0x4f2a40}
+        want: {a 0x4f2a40}
+        reason: .F: non-nil funcs are never DeepEqual
+FAIL
+`
+
+	c := globalCmd{Monochrome: true, Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.colorize(strings.NewReader(input), buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != input {
+		t.Errorf("\ngot:\n%s\nwant:\n%s", got, input)
+	}
+
+	// colored: the reason is not a part of want, so nothing is colored
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+	color.NoColor = false // as a terminal
+
+	c = globalCmd{Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err := c.colorize(strings.NewReader(input), buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != input {
+		t.Errorf("\ngot:\n%q\nwant:\n%q", got, input)
+	}
+
+	rep, err := parseReport(strings.NewReader("=== RUN   TestX\n" + input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := rep.Packages[0].Tests[0].Failures[0]
+	if f.Want != "{a 0x4f2a40}" || f.Desc != "reason: .F: non-nil funcs are never DeepEqual" {
+		t.Errorf("want: %q, desc: %q", f.Want, f.Desc)
+	}
+}
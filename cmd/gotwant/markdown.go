@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shu-go/gotwant/diff"
+)
+
+// writeMarkdown writes a compact summary of failures in Markdown.
+// It is suitable for PR comments and $GITHUB_STEP_SUMMARY.
+func (c globalCmd) writeMarkdown(w io.Writer, rep *report) error {
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "## gotwant: %d failed, %d passed, %d skipped\n", rep.Failed(), rep.Passed(), rep.Skipped())
+
+	for _, p := range rep.Packages {
+		for _, t := range p.Tests {
+			if t.Status != "FAIL" || len(t.Failures) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(sb, "\n### :x: `%s`", t.Name)
+			if p.Name != "" {
+				fmt.Fprintf(sb, " (`%s`)", p.Name)
+			}
+			sb.WriteString("\n")
+
+			for _, f := range t.Failures {
+				fmt.Fprintf(sb, "\n`%s`\n", f.Location)
+				if f.Desc != "" {
+					fmt.Fprintf(sb, "\n%s\n", f.Desc)
+				}
+				if f.HasGW {
+					sb.WriteString("\n")
+					// got and want as -/+ lines
+					diffs := diff.Compute(f.Got+"\n", f.Want+"\n", diff.Line)
+					writeFenced(sb, "diff", diff.RenderString(diff.LineRenderer{}, diffs))
+				}
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeFenced writes content in a fenced code block.
+// The fence gets longer than any backtick run in content.
+func writeFenced(sb *strings.Builder, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+
+	sb.WriteString(fence + lang + "\n")
+	sb.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fence + "\n")
+}
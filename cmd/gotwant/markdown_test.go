@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	rep, err := parseReport(strings.NewReader(testLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := globalCmd{Granularity: "char"}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+
+	sb := &strings.Builder{}
+	if err := c.writeMarkdown(sb, rep); err != nil {
+		t.Fatal(err)
+	}
+	want := "## gotwant: 2 failed, 2 passed, 1 skipped\n" +
+		"\n### :x: `TestB/sub` (`example.com/foo`)\n" +
+		"\n`b_test.go:10`\n" +
+		"\n```diff\n- abc\n+ axc\n```\n"
+	if got := sb.String(); got != want {
+		t.Errorf("\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteFenced(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: "a\n", want: "```diff\na\n```\n"},
+		{content: "a", want: "```diff\na\n```\n"},
+		{content: "- ```go\n+ ``go\n", want: "````diff\n- ```go\n+ ``go\n````\n"},
+		{content: "````", want: "`````diff\n````\n`````\n"},
+	}
+	for _, tc := range tests {
+		sb := &strings.Builder{}
+		writeFenced(sb, "diff", tc.content)
+		if got := sb.String(); got != tc.want {
+			t.Errorf("%q:\ngot:\n%s\nwant:\n%s", tc.content, got, tc.want)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	dir := t.TempDir()
+	md := filepath.Join(dir, "summary.md")
+	html := filepath.Join(dir, "report.html")
+
+	for _, path := range []string{md, html} {
+		if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := globalCmd{Granularity: "char", Markdown: md, HTML: html, Append: true}
+	if err := c.Before(); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := c.writeReports([]byte(testLog)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// markdown is appended
+	content, err := os.ReadFile(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(content); !strings.HasPrefix(s, "existing\n## gotwant: ") || strings.Count(s, "## gotwant: ") != 2 {
+		t.Errorf("markdown:\n%s", s)
+	}
+
+	// HTML is overwritten
+	content, err = os.ReadFile(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(content); !strings.HasPrefix(s, "<!DOCTYPE html>") || strings.Count(s, "<!DOCTYPE html>") != 1 {
+		t.Errorf("html:\n%s", s)
+	}
+
+	// without --append, markdown is overwritten too
+	c.Append = false
+	if _, err := c.writeReports([]byte(testLog)); err != nil {
+		t.Fatal(err)
+	}
+	content, err = os.ReadFile(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(content); !strings.HasPrefix(s, "## gotwant: ") || strings.Count(s, "## gotwant: ") != 1 {
+		t.Errorf("markdown:\n%s", s)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// report is a structured `go test` log.
+type report struct {
+	Packages []*pkgResult
+}
+
+type pkgResult struct {
+	Name    string
+	Status  string // ok, FAIL or ? (no test files)
+	Elapsed string
+	Tests   []*testResult
+}
+
+type testResult struct {
+	Name     string
+	Status   string  // PASS, FAIL, SKIP or "" (unknown)
+	Elapsed  float64 // seconds
+	Output   []string
+	Failures []*failure
+}
+
+// failure is an output of t.Errorf and its friends.
+type failure struct {
+	Location string // file:line
+	Desc     string
+	Got      string
+	Want     string
+	HasGW    bool // Got and Want are available
+
+	indent int
+	lines  []string // without location and indentation
+}
+
+var (
+	gwRE       = regexp.MustCompile(`^(\s*)(got:|want:)\s( *)`)
+	diffLineRE = regexp.MustCompile(`^( *)diff:\s`)
+	reasonRE   = regexp.MustCompile(`^( *)reason:\s`)
+	runRE      = regexp.MustCompile(`^=== (RUN|CONT|NAME|PAUSE)\s+(\S+)`)
+	resultRE   = regexp.MustCompile(`^(\s*)--- (PASS|FAIL|SKIP): (\S+) \(([\d.]+)s\)`)
+	pkgRE      = regexp.MustCompile(`^(ok|FAIL|\?)\s+(\S+)\s*(.*)$`)
+	locatorRE  = regexp.MustCompile(`^(\s+)(\S+\.go:\d+):\s?(.*)$`)
+)
+
+// parseReport reads a `go test` log (with or without -v).
+func parseReport(r io.Reader) (*report, error) {
+	rep := &report{}
+
+	var tests []*testResult // tests of the current package
+	testsByName := make(map[string]*testResult)
+	getTest := func(name string) *testResult {
+		if t, found := testsByName[name]; found {
+			return t
+		}
+		t := &testResult{Name: name}
+		testsByName[name] = t
+		tests = append(tests, t)
+		return t
+	}
+
+	var curr *testResult
+	var entry *failure
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+
+		if m := runRE.FindStringSubmatch(line); m != nil {
+			curr = getTest(m[2])
+			entry = nil
+			continue
+		}
+
+		if m := resultRE.FindStringSubmatch(line); m != nil {
+			curr = getTest(m[3])
+			curr.Status = m[2]
+			curr.Elapsed, _ = strconv.ParseFloat(m[4], 64)
+			entry = nil
+			continue
+		}
+
+		if m := pkgRE.FindStringSubmatch(line); m != nil && strings.Contains(line, "\t") {
+			pkg := &pkgResult{
+				Name:    m[2],
+				Status:  m[1],
+				Elapsed: m[3],
+				Tests:   tests,
+			}
+			rep.Packages = append(rep.Packages, pkg)
+
+			tests = nil
+			testsByName = make(map[string]*testResult)
+			curr = nil
+			entry = nil
+			continue
+		}
+
+		if curr == nil {
+			continue
+		}
+
+		if m := locatorRE.FindStringSubmatch(line); m != nil {
+			entry = &failure{
+				Location: m[2],
+				indent:   len(m[1]),
+				lines:    []string{m[3]},
+			}
+			curr.Failures = append(curr.Failures, entry)
+			curr.Output = append(curr.Output, strings.TrimLeft(line, " "))
+			continue
+		}
+
+		if entry != nil && countIndent(line) > entry.indent {
+			contIndent := strings.Repeat(" ", entry.indent+4)
+			entry.lines = append(entry.lines, strings.TrimPrefix(line, contIndent))
+			curr.Output = append(curr.Output, strings.TrimPrefix(line, contIndent))
+			continue
+		}
+		entry = nil
+
+		if line == "PASS" || line == "FAIL" {
+			continue
+		}
+		curr.Output = append(curr.Output, strings.TrimLeft(line, " "))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	// tests without package summary (e.g. the log is truncated)
+	if len(tests) != 0 {
+		rep.Packages = append(rep.Packages, &pkgResult{Tests: tests})
+	}
+
+	for _, p := range rep.Packages {
+		for _, t := range p.Tests {
+			for _, f := range t.Failures {
+				f.parseGotWant()
+			}
+		}
+	}
+
+	return rep, nil
+}
+
+// parseGotWant splits lines into Desc, Got and Want, in the same manner as globalCmd.Run.
+func (f *failure) parseGotWant() {
+	const (
+		readingDesc = iota
+		readingGot
+		readingWant
+		readingDiff
+		readingRest
+	)
+
+	var desc []string
+	s := readingDesc
+	for _, line := range f.lines {
+		if m := gwRE.FindStringSubmatch(line); m != nil && len(m[1]) == 0 {
+			if strings.HasPrefix(m[2], "got") {
+				s = readingGot
+				f.Got = line[len(m[0]):]
+				f.HasGW = true
+			} else {
+				s = readingWant
+				f.Want = line[len(m[0]):]
+			}
+			continue
+		}
+
+		cont, isCont := strings.CutPrefix(line, strings.Repeat(" ", 6))
+		if m := diffLineRE.FindStringSubmatch(line); s == readingWant && m != nil && len(m[1]) == 0 {
+			// a plain-text diff (gotwant.Diff) at the column of want is dropped; reports have their own diffs.
+			s = readingDiff
+			continue
+		}
+		if m := reasonRE.FindStringSubmatch(line); s == readingWant && m != nil && len(m[1]) == 0 {
+			// a reason of being not DeepEqual at the column of want ends want.
+			s = readingRest
+			desc = append(desc, line)
+			continue
+		}
+		switch {
+		case s == readingDiff && isCont:
+			// nop
+		case s == readingGot && isCont:
+			f.Got += "\n" + cont
+		case s == readingWant && isCont:
+			f.Want += "\n" + cont
+		case s == readingDesc:
+			desc = append(desc, line)
+		default:
+			s = readingRest
+			desc = append(desc, line)
+		}
+	}
+
+	f.Desc = strings.TrimSpace(strings.Join(desc, "\n"))
+}
+
+// Failed counts failed tests.
+func (r *report) Failed() int {
+	return r.count("FAIL")
+}
+
+// Passed counts passed tests.
+func (r *report) Passed() int {
+	return r.count("PASS")
+}
+
+// Skipped counts skipped tests.
+func (r *report) Skipped() int {
+	return r.count("SKIP")
+}
+
+func (r *report) count(status string) int {
+	n := 0
+	for _, p := range r.Packages {
+		for _, t := range p.Tests {
+			if t.Status == status {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// writeReports writes reports specified by options.
+// It returns true if any of reports is written to stdout.
+func (c globalCmd) writeReports(input []byte) (toStdout bool, err error) {
+	writers := []struct {
+		path       string
+		write      func(io.Writer, *report) error
+		appendable bool // false: appending makes an invalid document (two root elements)
+	}{
+		{c.HTML, c.writeHTML, false},
+		{c.JUnit, c.writeJUnit, false},
+		{c.Markdown, c.writeMarkdown, true},
+	}
+
+	var rep *report
+	for _, w := range writers {
+		if w.path == "" {
+			continue
+		}
+
+		if rep == nil {
+			rep, err = parseReport(bytes.NewReader(input))
+			if err != nil {
+				return false, err
+			}
+		}
+
+		if w.path == "-" {
+			toStdout = true
+			if err := w.write(os.Stdout, rep); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if c.Append && w.appendable {
+			flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		f, err := os.OpenFile(w.path, flag, 0o644)
+		if err != nil {
+			return false, err
+		}
+		err = w.write(f, rep)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return toStdout, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// testLog is a `go test -v ./...` log of two packages.
+const testLog = `=== RUN   TestA
+--- PASS: TestA (0.01s)
+=== RUN   TestB
+=== RUN   TestB/sub
+    b_test.go:10: 
+        got:  abc
+        want: axc
+--- FAIL: TestB (0.02s)
+    --- FAIL: TestB/sub (0.01s)
+=== RUN   TestC
+    c_test.go:5: not yet
+--- SKIP: TestC (0.00s)
+FAIL
+FAIL	example.com/foo	0.031s
+=== RUN   TestD
+--- PASS: TestD (1.50s)
+PASS
+ok  	example.com/bar	1.502s
+`
+
+func TestParseReport(t *testing.T) {
+	rep, err := parseReport(strings.NewReader(testLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rep.Failed() != 2 || rep.Passed() != 2 || rep.Skipped() != 1 {
+		t.Errorf("failed=%d, passed=%d, skipped=%d", rep.Failed(), rep.Passed(), rep.Skipped())
+	}
+
+	if len(rep.Packages) != 2 {
+		t.Fatalf("%d packages", len(rep.Packages))
+	}
+	foo, bar := rep.Packages[0], rep.Packages[1]
+	if foo.Name != "example.com/foo" || foo.Status != "FAIL" || len(foo.Tests) != 4 {
+		t.Errorf("%s %s %d tests", foo.Name, foo.Status, len(foo.Tests))
+	}
+	if bar.Name != "example.com/bar" || bar.Status != "ok" || len(bar.Tests) != 1 || bar.Tests[0].Elapsed != 1.5 {
+		t.Errorf("%s %s %d tests", bar.Name, bar.Status, len(bar.Tests))
+	}
+
+	sub := foo.Tests[2]
+	if sub.Name != "TestB/sub" || sub.Status != "FAIL" || sub.Elapsed != 0.01 || len(sub.Failures) != 1 {
+		t.Fatalf("%s %s %v %d failures", sub.Name, sub.Status, sub.Elapsed, len(sub.Failures))
+	}
+	f := sub.Failures[0]
+	if f.Location != "b_test.go:10" || !f.HasGW || f.Got != "abc" || f.Want != "axc" {
+		t.Errorf("%#v", f)
+	}
+
+	skip := foo.Tests[3]
+	if skip.Status != "SKIP" || len(skip.Failures) != 1 || skip.Failures[0].Desc != "not yet" {
+		t.Errorf("%s %#v", skip.Status, skip.Failures)
+	}
+}
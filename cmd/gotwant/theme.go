@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// theme is a set of colors used to show edits.
+type theme struct {
+	Insert      *color.Color
+	InsertSpace *color.Color // for whitespace runs
+	Delete      *color.Color
+	DeleteSpace *color.Color // for whitespace runs
+
+	// insert-space and delete-space are set explicitly (not derived from insert and delete)
+	insertSpaceSet, deleteSpaceSet bool
+}
+
+// themes are built-in themes.
+// Each value is a theme spec (see theme.apply).
+var themes = map[string]string{
+	"default": "insert:green,bold; delete:red,bold",
+	// for light backgrounds
+	"light": "insert:22,bold; delete:124,bold",
+	// for red-green colorblindness
+	"colorblind": "insert:hi-blue,bold; delete:yellow,bold",
+	// Okabe-Ito palette, colorblind-safe
+	"okabe-ito": "insert:#0072b2,bold; delete:#e69f00,bold",
+	// no hue at all
+	"mono": "insert:bold,underline; delete:reverse",
+}
+
+func themeNames() []string {
+	return []string{"default", "light", "colorblind", "okabe-ito", "mono"}
+}
+
+type colorDepth uint8
+
+const (
+	depth16 colorDepth = iota
+	depth256
+	depthTrue
+)
+
+// detectColorDepth decides color depth from the name (auto, 16, 256, truecolor) and the environment.
+func detectColorDepth(name string) (colorDepth, error) {
+	switch strings.ToLower(name) {
+	case "16":
+		return depth16, nil
+	case "256":
+		return depth256, nil
+	case "true", "truecolor", "24bit":
+		return depthTrue, nil
+	case "", "auto":
+	default:
+		return depth16, fmt.Errorf("unknown color depth %q", name)
+	}
+
+	// FORCE_COLOR=1,2,3 (same as chalk)
+	switch os.Getenv("FORCE_COLOR") {
+	case "1":
+		return depth16, nil
+	case "2":
+		return depth256, nil
+	case "3":
+		return depthTrue, nil
+	}
+
+	colorterm := strings.ToLower(os.Getenv("COLORTERM"))
+	if colorterm == "truecolor" || colorterm == "24bit" {
+		return depthTrue, nil
+	}
+	if strings.Contains(os.Getenv("TERM"), "256") {
+		return depth256, nil
+	}
+	return depth16, nil
+}
+
+// forceColor tells whether FORCE_COLOR is set.
+// NO_COLOR takes precedence over FORCE_COLOR.
+func forceColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	v, ok := os.LookupEnv("FORCE_COLOR")
+	if !ok {
+		return false
+	}
+	return v != "0" && !strings.EqualFold(v, "false")
+}
+
+// loadTheme builds a theme.
+// name is a built-in theme, file is a theme file (may be empty) and spec is a theme spec (may be empty).
+// Latter ones override former ones.
+func loadTheme(name, file, spec string, depth colorDepth) (*theme, error) {
+	if name == "" {
+		name = "default"
+	}
+	base, found := themes[strings.ToLower(name)]
+	if !found {
+		return nil, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(themeNames(), ", "))
+	}
+
+	th := &theme{}
+	if err := th.apply(base, depth); err != nil {
+		return nil, err
+	}
+
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := th.apply(string(content), depth); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	if err := th.apply(spec, depth); err != nil {
+		return nil, err
+	}
+
+	return th, nil
+}
+
+// apply parses a theme spec and overrides th.
+//
+// A theme spec is a list of KEY=STYLE (or KEY:STYLE) separated by ';' or newlines.
+// KEY is one of insert, delete, insert-space, delete-space.
+// If insert-space (delete-space) is omitted, insert (delete) with underline is used.
+// Lines beginning with "//" are ignored.
+//
+// STYLE is a list of the followings separated by ',' or '+':
+//   - modifiers: bold, faint, italic, underline, blink, reverse, crossedout
+//   - 16 colors: black, red, green, yellow, blue, magenta, cyan, white, hi-red, ...
+//   - 256 colors: 0 - 255
+//   - true colors: #rrggbb
+//   - background colors: bg:red, bg:22, bg:#rrggbb
+//
+// An explicit insert-space (delete-space) is kept even if insert (delete) is overridden by a latter spec.
+func (th *theme) apply(spec string, depth colorDepth) error {
+	sc := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(spec, ";", "\n")))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		pos := strings.IndexAny(line, "=:")
+		if pos == -1 {
+			return fmt.Errorf("invalid theme entry %q", line)
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:pos]))
+		value := line[pos+1:]
+
+		c, err := parseStyle(value, depth)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+
+		switch key {
+		case "insert":
+			th.Insert = c
+			if !th.insertSpaceSet {
+				th.InsertSpace = underlined(value, depth)
+			}
+		case "delete":
+			th.Delete = c
+			if !th.deleteSpaceSet {
+				th.DeleteSpace = underlined(value, depth)
+			}
+		case "insert-space":
+			th.InsertSpace = c
+			th.insertSpaceSet = true
+		case "delete-space":
+			th.DeleteSpace = c
+			th.deleteSpaceSet = true
+		default:
+			return fmt.Errorf("unknown theme key %q", key)
+		}
+	}
+	return sc.Err()
+}
+
+func underlined(style string, depth colorDepth) *color.Color {
+	c, _ := parseStyle(style, depth)
+	return c.Add(color.Underline)
+}
+
+var modifiers = map[string]color.Attribute{
+	"bold":       color.Bold,
+	"faint":      color.Faint,
+	"italic":     color.Italic,
+	"underline":  color.Underline,
+	"blink":      color.BlinkSlow,
+	"reverse":    color.ReverseVideo,
+	"crossedout": color.CrossedOut,
+}
+
+var basicColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// parseStyle parses STYLE (see theme.apply) into a color.
+func parseStyle(style string, depth colorDepth) (*color.Color, error) {
+	c := color.New()
+
+	fields := strings.FieldsFunc(style, func(r rune) bool {
+		return r == ',' || r == '+' || r == ' ' || r == '\t'
+	})
+	for _, f := range fields {
+		f = strings.ToLower(f)
+
+		if a, found := modifiers[f]; found {
+			c.Add(a)
+			continue
+		}
+
+		bg := false
+		if strings.HasPrefix(f, "bg:") {
+			bg = true
+			f = f[len("bg:"):]
+		}
+
+		attrs, err := parseColor(f, bg, depth)
+		if err != nil {
+			return nil, err
+		}
+		c.Add(attrs...)
+	}
+
+	return c, nil
+}
+
+// parseColor parses a color name, a 256 color number or #rrggbb,
+// and returns attributes downgraded to depth.
+func parseColor(s string, bg bool, depth colorDepth) ([]color.Attribute, error) {
+	base := color.FgBlack
+	if bg {
+		base = color.BgBlack
+	}
+
+	name, hi := strings.CutPrefix(s, "hi-")
+	for i, n := range basicColors {
+		if n == name {
+			if hi {
+				return []color.Attribute{base + color.FgHiBlack - color.FgBlack + color.Attribute(i)}, nil
+			}
+			return []color.Attribute{base + color.Attribute(i)}, nil
+		}
+	}
+
+	var r, g, b int
+	var n int
+	switch {
+	case strings.HasPrefix(s, "#"):
+		v, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil || len(s) != 7 {
+			return nil, fmt.Errorf("invalid color %q", s)
+		}
+		r, g, b = int(v>>16&0xff), int(v>>8&0xff), int(v&0xff)
+		if depth == depthTrue {
+			return []color.Attribute{base + 8, 2, color.Attribute(r), color.Attribute(g), color.Attribute(b)}, nil
+		}
+		n = nearest256(r, g, b)
+
+	default:
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 || 255 < v {
+			return nil, fmt.Errorf("invalid color %q", s)
+		}
+		n = v
+	}
+
+	if depth != depth16 {
+		return []color.Attribute{base + 8, 5, color.Attribute(n)}, nil
+	}
+
+	if n < 16 {
+		if n < 8 {
+			return []color.Attribute{base + color.Attribute(n)}, nil
+		}
+		return []color.Attribute{base + color.FgHiBlack - color.FgBlack + color.Attribute(n-8)}, nil
+	}
+	r, g, b = rgb256(n)
+	return []color.Attribute{base + color.Attribute(nearest8(r, g, b))}, nil
+}
+
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// rgb256 returns RGB of the 256 color n (n >= 16).
+func rgb256(n int) (r, g, b int) {
+	if n >= 232 {
+		v := 8 + (n-232)*10
+		return v, v, v
+	}
+	n -= 16
+	return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+}
+
+// nearest256 returns the nearest 256 color (in the 6x6x6 cube or the grayscale ramp).
+func nearest256(r, g, b int) int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(l-v) < abs(cubeLevels[best]-v) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	cube := 16 + 36*level(r) + 6*level(g) + level(b)
+	gray := 232 + min(max((r+g+b)/3-8, 0)/10, 23)
+
+	cr, cg, cb := rgb256(cube)
+	gr, gg, gb := rgb256(gray)
+	if dist(r, g, b, gr, gg, gb) < dist(r, g, b, cr, cg, cb) {
+		return gray
+	}
+	return cube
+}
+
+// nearest8 returns the nearest basic color index (0: black ... 7: white).
+func nearest8(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i := range basicColors {
+		cr, cg, cb := 0, 0, 0
+		if i&1 != 0 {
+			cr = 205
+		}
+		if i&2 != 0 {
+			cg = 205
+		}
+		if i&4 != 0 {
+			cb = 205
+		}
+		if d := dist(r, g, b, cr, cg, cb); bestDist == -1 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+func dist(r1, g1, b1, r2, g2, b2 int) int {
+	return (r1-r2)*(r1-r2) + (g1-g2)*(g1-g2) + (b1-b2)*(b1-b2)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
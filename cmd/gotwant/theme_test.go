@@ -0,0 +1,141 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s     string
+		bg    bool
+		depth colorDepth
+		want  []color.Attribute
+	}{
+		{s: "red", depth: depth16, want: []color.Attribute{color.FgRed}},
+		{s: "hi-blue", depth: depth16, want: []color.Attribute{color.FgHiBlue}},
+		{s: "green", bg: true, depth: depth16, want: []color.Attribute{color.BgGreen}},
+
+		// 256 colors
+		{s: "22", depth: depth256, want: []color.Attribute{38, 5, 22}},
+		{s: "22", bg: true, depth: depthTrue, want: []color.Attribute{48, 5, 22}},
+		{s: "3", depth: depth16, want: []color.Attribute{color.FgYellow}},
+		{s: "9", depth: depth16, want: []color.Attribute{color.FgHiRed}},
+		{s: "46", depth: depth16, want: []color.Attribute{color.FgGreen}},
+		{s: "255", depth: depth16, want: []color.Attribute{color.FgWhite}},
+
+		// true colors
+		{s: "#0072b2", depth: depthTrue, want: []color.Attribute{38, 2, 0x00, 0x72, 0xb2}},
+		{s: "#ff0000", depth: depth256, want: []color.Attribute{38, 5, 196}},
+		{s: "#808080", depth: depth256, want: []color.Attribute{38, 5, 244}},
+		{s: "#ff0000", depth: depth16, want: []color.Attribute{color.FgRed}},
+		{s: "#0000ff", bg: true, depth: depth16, want: []color.Attribute{color.BgBlue}},
+	}
+	for _, tc := range tests {
+		got, err := parseColor(tc.s, tc.bg, tc.depth)
+		if err != nil {
+			t.Errorf("%s: %v", tc.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s (bg=%v, depth=%v):\ngot:  %v\nwant: %v", tc.s, tc.bg, tc.depth, got, tc.want)
+		}
+	}
+
+	for _, s := range []string{"purple", "256", "-1", "#12345", "#gggggg"} {
+		if _, err := parseColor(s, false, depthTrue); err == nil {
+			t.Errorf("%s: must be an error", s)
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		style string
+		depth colorDepth
+		want  *color.Color
+	}{
+		{style: "green,bold", depth: depth16, want: color.New(color.FgGreen, color.Bold)},
+		{style: "bold+underline", depth: depth16, want: color.New(color.Bold, color.Underline)},
+		{style: "Red, bg:white", depth: depth16, want: color.New(color.FgRed, color.BgWhite)},
+		{style: "bg:22", depth: depth256, want: color.New(48, 5, 22)},
+		{style: "", depth: depth16, want: color.New()},
+	}
+	for _, tc := range tests {
+		got, err := parseStyle(tc.style, tc.depth)
+		if err != nil {
+			t.Errorf("%s: %v", tc.style, err)
+			continue
+		}
+		if !got.Equals(tc.want) {
+			t.Errorf("%s:\ngot:  %v\nwant: %v", tc.style, got, tc.want)
+		}
+	}
+
+	if _, err := parseStyle("bold,bg:nothing", depth16); err == nil {
+		t.Error("must be an error")
+	}
+}
+
+func TestThemeApply(t *testing.T) {
+	tests := []struct {
+		specs            []string
+		insert, insSpace *color.Color
+		delete, delSpace *color.Color
+	}{
+		{
+			specs:    []string{"insert:green; delete:red"},
+			insert:   color.New(color.FgGreen),
+			insSpace: color.New(color.FgGreen, color.Underline),
+			delete:   color.New(color.FgRed),
+			delSpace: color.New(color.FgRed, color.Underline),
+		},
+		{
+			// an explicit insert-space in a theme file is kept by --colors insert:...
+			specs:    []string{"insert:green; delete:red", "insert-space:bg:green", "insert:blue"},
+			insert:   color.New(color.FgBlue),
+			insSpace: color.New(color.BgGreen),
+			delete:   color.New(color.FgRed),
+			delSpace: color.New(color.FgRed, color.Underline),
+		},
+		{
+			// in any order
+			specs:    []string{"delete:red\n// comment\ndelete-space=reverse\ndelete=yellow"},
+			delete:   color.New(color.FgYellow),
+			delSpace: color.New(color.ReverseVideo),
+		},
+	}
+	for i, tc := range tests {
+		th := &theme{}
+		for _, spec := range tc.specs {
+			if err := th.apply(spec, depth16); err != nil {
+				t.Fatalf("[%d] %v", i, err)
+			}
+		}
+
+		for _, c := range []struct {
+			name      string
+			got, want *color.Color
+		}{
+			{"insert", th.Insert, tc.insert},
+			{"insert-space", th.InsertSpace, tc.insSpace},
+			{"delete", th.Delete, tc.delete},
+			{"delete-space", th.DeleteSpace, tc.delSpace},
+		} {
+			if c.want == nil {
+				continue
+			}
+			if c.got == nil || !c.got.Equals(c.want) {
+				t.Errorf("[%d] %s:\ngot:  %v\nwant: %v", i, c.name, c.got, c.want)
+			}
+		}
+	}
+
+	for _, spec := range []string{"insert", "unknown:red", "insert:nothing"} {
+		if err := (&theme{}).apply(spec, depth16); err == nil {
+			t.Errorf("%s: must be an error", spec)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Package diff computes and renders differences between got and want.
+// It is shared by gotwant and its command (cmd/gotwant).
+package diff
+
+import (
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Diff is a single edit (or equality).
+type Diff = diffmatchpatch.Diff
+
+// Operation is a type of Diff.
+type Operation = diffmatchpatch.Operation
+
+const (
+	Delete = diffmatchpatch.DiffDelete // only in got
+	Insert = diffmatchpatch.DiffInsert // only in want
+	Equal  = diffmatchpatch.DiffEqual  // in both
+)
+
+// Compute computes diffs between got and want at granularity g.
+func Compute(got, want string, g Granularity) []Diff {
+	return ComputeWith(diffmatchpatch.New(), got, want, g)
+}
+
+// PlainText renders diffs inline without any escape sequences, like `git diff --word-diff=plain`.
+//
+//	[-deleted-]{+inserted+}
+func PlainText(diffs []Diff) string {
+	return RenderString(TextRenderer{}, diffs)
+}
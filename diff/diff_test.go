@@ -0,0 +1,124 @@
+package diff_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/gotwant/diff"
+)
+
+func TestCompute(t *testing.T) {
+	got := "the quick brown fox\njumps over"
+	want := "the quack brown cat\njumps over"
+
+	gotwant.Test(t, diff.PlainText(diff.Compute(got, want, diff.Char)), "the qu[-i-]{+a+}ck brown [-fox-]{+cat+}\njumps over")
+	gotwant.Test(t, diff.PlainText(diff.Compute(got, want, diff.Word)), "the [-quick-]{+quack+} brown [-fox-]{+cat+}\njumps over")
+	gotwant.Test(t, diff.PlainText(diff.Compute(got, want, diff.Line)), "[-the quick brown fox\n-]{+the quack brown cat\n+}jumps over")
+
+	_, err := diff.ParseGranularity("sentence")
+	gotwant.TestError(t, err, "unknown granularity")
+}
+
+func TestLines(t *testing.T) {
+	diffs := []diff.Diff{
+		{Type: diff.Equal, Text: "aaa\nb"},
+		{Type: diff.Delete, Text: "b\nc"},
+		{Type: diff.Insert, Text: "a"},
+	}
+
+	diffs = diff.SplitByNewline(diffs)
+	gotwant.Test(t, diffs, []diff.Diff{
+		{Type: diff.Equal, Text: "aaa\n"},
+		{Type: diff.Equal, Text: "b"},
+		{Type: diff.Delete, Text: "b\n"},
+		{Type: diff.Delete, Text: "c"},
+		{Type: diff.Insert, Text: "a"},
+	})
+
+	diffs = diff.AddIndents(diffs, "  ")
+	gotwant.Test(t, diff.PlainText(diffs), "aaa\n  b[-b\n-]  [-c-]{+a+}")
+
+	gotwant.Test(t, diff.Filter(diffs, diff.Delete), []diff.Diff{
+		{Type: diff.Equal, Text: "aaa\n"},
+		{Type: diff.Equal, Text: "  "},
+		{Type: diff.Equal, Text: "b"},
+		{Type: diff.Equal, Text: "  "},
+		{Type: diff.Insert, Text: "a"},
+	})
+}
+
+func TestSuppressPrefix(t *testing.T) {
+	diffs := []diff.Diff{
+		{Type: diff.Equal, Text: "a"},
+		{Type: diff.Insert, Text: "b\n    c"},
+	}
+	gotwant.Test(t, diff.SuppressPrefix(diffs, "    "), []diff.Diff{
+		{Type: diff.Equal, Text: "a"},
+		{Type: diff.Insert, Text: "b\n"},
+		{Type: diff.Equal, Text: "    "},
+		{Type: diff.Insert, Text: "c"},
+	})
+}
+
+func TestSplit(t *testing.T) {
+	spans := diff.Split([]diff.Diff{{Type: diff.Insert, Text: "a b\t\tc"}})
+	gotwant.Test(t, spans, []diff.Span{
+		{Diff: diff.Diff{Type: diff.Insert, Text: "a"}},
+		{Diff: diff.Diff{Type: diff.Insert, Text: " "}, IsSpace: true},
+		{Diff: diff.Diff{Type: diff.Insert, Text: "b"}},
+		{Diff: diff.Diff{Type: diff.Insert, Text: "\t\t"}, IsSpace: true},
+		{Diff: diff.Diff{Type: diff.Insert, Text: "c"}},
+	})
+}
+
+func TestWhitespace(t *testing.T) {
+	gotwant.Test(t, diff.NormalizeEOL("a\r\nb\rc\n"), "a\nb\nc\n")
+	gotwant.Test(t, diff.NormalizeSpace("a  b\t \nc "), "a b\nc")
+
+	diffs := diff.Visualize([]diff.Diff{
+		{Type: diff.Equal, Text: "a b\u200b"},
+		{Type: diff.Insert, Text: " \t\r\n"},
+	})
+	gotwant.Test(t, diff.PlainText(diffs), "a b<ZWSP>{+·→␍↵\n+}")
+}
+
+func TestDenormalize(t *testing.T) {
+	got, want := "  a  b \r\nc\tx", "a b\nc  y  "
+	diffs := diff.Compute(diff.NormalizeSpace(diff.NormalizeEOL(got)), diff.NormalizeSpace(diff.NormalizeEOL(want)), diff.Char)
+
+	gotDiffs, wantDiffs := diff.Denormalize(diffs, got, want, true, true)
+	gotwant.Test(t, diff.PlainText(diff.Filter(gotDiffs, diff.Insert)), "[-  -]a  b \r\nc\t[-x-]")
+	gotwant.Test(t, diff.PlainText(diff.Filter(wantDiffs, diff.Delete)), "a b\nc  {+y+}  ")
+}
+
+type bracket string
+
+func (b bracket) Fprint(w io.Writer, a ...interface{}) (int, error) {
+	return fmt.Fprintf(w, "<%s>%s</%s>", b, fmt.Sprint(a...), b)
+}
+
+func TestRenderer(t *testing.T) {
+	diffs := []diff.Diff{
+		{Type: diff.Equal, Text: "a<"},
+		{Type: diff.Delete, Text: "b c"},
+		{Type: diff.Insert, Text: "d"},
+	}
+
+	gotwant.Test(t, diff.RenderString(diff.TextRenderer{}, diffs), "a<[-b c-]{+d+}")
+	gotwant.Test(t, diff.RenderString(diff.TextRenderer{DeleteStart: "(", DeleteEnd: ")", InsertStart: "{", InsertEnd: "}"}, diffs), "a<(b c){d}")
+
+	r := diff.ANSIRenderer{
+		Insert:      bracket("i"),
+		InsertSpace: bracket("is"),
+		Delete:      bracket("d"),
+		DeleteSpace: bracket("ds"),
+	}
+	gotwant.Test(t, diff.RenderString(r, diffs), "a<<d>b</d><ds> </ds><d>c</d><i>d</i>")
+
+	gotwant.Test(t, diff.RenderString(diff.HTMLRenderer{}, diffs), `a&lt;<del>b</del><del class="space"> </del><del>c</del><ins>d</ins>`)
+
+	lines := diff.Compute("a\nb\nc", "a\nB\nc\nd\n", diff.Line)
+	gotwant.Test(t, diff.RenderString(diff.LineRenderer{}, lines), "  a\n- b\n- c\n+ B\n+ c\n+ d\n")
+}
@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Granularity is a unit of diffs.
+type Granularity string
+
+const (
+	Char Granularity = "char"
+	Word Granularity = "word"
+	Line Granularity = "line"
+)
+
+// ParseGranularity parses "char", "word" or "line".
+func ParseGranularity(s string) (Granularity, error) {
+	switch g := Granularity(s); g {
+	case Char, Word, Line:
+		return g, nil
+	default:
+		return Char, fmt.Errorf("unknown granularity %q (available: char, word, line)", s)
+	}
+}
+
+// ComputeWith computes diffs token by token, with dmp.
+// Each token is treated as a single character, so that a change is shown as a whole-token replacement.
+func ComputeWith(dmp *diffmatchpatch.DiffMatchPatch, text1, text2 string, g Granularity) []Diff {
+	var split func(string) []string
+	switch g {
+	case Line:
+		split = splitLines
+	case Word:
+		split = splitWords
+	default:
+		return dmp.DiffMain(text1, text2, true)
+	}
+
+	var tokens []string
+	tokenIndex := make(map[string]rune)
+	munge := func(text string) []rune {
+		var runes []rune
+		for _, t := range split(text) {
+			r, found := tokenIndex[t]
+			if !found {
+				r = tokenRune(len(tokens))
+				tokenIndex[t] = r
+				tokens = append(tokens, t)
+			}
+			runes = append(runes, r)
+		}
+		return runes
+	}
+
+	runes1, runes2 := munge(text1), munge(text2)
+	diffs := dmp.DiffMainRunes(runes1, runes2, false)
+
+	for i, d := range diffs {
+		sb := &strings.Builder{}
+		for _, r := range d.Text {
+			sb.WriteString(tokens[runeToken(r)])
+		}
+		diffs[i].Text = sb.String()
+	}
+	return diffs
+}
+
+// tokenRune encodes a token index into a valid rune (skipping surrogates).
+func tokenRune(index int) rune {
+	r := rune(index + 1)
+	if r >= 0xD800 {
+		r += 0x800
+	}
+	return r
+}
+
+func runeToken(r rune) int {
+	if r >= 0xD800+0x800 {
+		r -= 0x800
+	}
+	return int(r) - 1
+}
+
+// splitLines splits text into lines, each including its trailing newline.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// splitWords splits text into words, whitespace runs, newlines and other single characters.
+func splitWords(text string) []string {
+	const (
+		other = iota
+		word
+		space
+	)
+	class := func(r rune) int {
+		switch {
+		case r == '\n':
+			return other
+		case unicode.IsSpace(r):
+			return space
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return word
+		default:
+			return other
+		}
+	}
+
+	var tokens []string
+	start := 0
+	for start < len(text) {
+		r, size := utf8.DecodeRuneInString(text[start:])
+		c := class(r)
+		end := start + size
+		if c != other {
+			for end < len(text) {
+				r, size := utf8.DecodeRuneInString(text[end:])
+				if class(r) != c {
+					break
+				}
+				end += size
+			}
+		}
+		tokens = append(tokens, text[start:end])
+		start = end
+	}
+	return tokens
+}
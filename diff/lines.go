@@ -0,0 +1,181 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Filter removes diffs of the given type.
+// It is used to show only one side (got or want) of the edits.
+func Filter(diffs []Diff, typ Operation) []Diff {
+	results := make([]Diff, 0, len(diffs))
+	for _, d := range diffs {
+		if d.Type == typ {
+			continue
+		}
+		results = append(results, d)
+	}
+	return results
+}
+
+// Span is a part of Diff, either a whitespace run or not.
+type Span struct {
+	Diff
+	IsSpace bool // consists of whitespaces or unprintable characters
+}
+
+// SuppressPrefix turns runs of linePrefix in edits into equalities,
+// so that indentations added by AddIndents are not decorated as edits.
+func SuppressPrefix(diffs []Diff, linePrefix string) []Diff {
+	result := make([]Diff, 0, len(diffs)*2)
+
+	result = slices.Insert(result, 0, diffs...)
+
+	suppressLen := len(linePrefix)
+	if suppressLen == 0 {
+		return result
+	}
+
+	index := len(result) - 1
+	for index >= 0 {
+		if result[index].Type == Equal {
+			index--
+			continue
+		}
+
+		insIndex := index
+		for {
+			pos := strings.Index(result[insIndex].Text, linePrefix)
+			if pos == -1 || result[insIndex].Text == linePrefix {
+				break
+			}
+
+			d := result[insIndex]
+
+			newD := d
+			newD.Type = Equal
+			newD.Text = linePrefix
+			result[insIndex] = newD
+
+			back := 0
+
+			if pos+suppressLen < len(d.Text) {
+				newD.Type = d.Type
+				newD.Text = d.Text[pos+suppressLen:]
+				result = slices.Insert(result, insIndex+1, newD)
+
+				back++
+			}
+
+			if pos > 0 {
+				newD := d
+				newD.Text = d.Text[:pos]
+				result = slices.Insert(result, insIndex, newD)
+
+				back++
+			}
+
+			insIndex += back
+		}
+
+		index--
+	}
+
+	return result
+}
+
+// SplitByNewline splits diffs so that each diff ends at a newline (or the end).
+func SplitByNewline(diffs []Diff) []Diff {
+	results := make([]Diff, 0, len(diffs))
+
+	for _, d := range diffs {
+		dText := d.Text
+		for {
+			pos := strings.Index(dText, "\n")
+			if pos == -1 {
+				break
+			}
+
+			newD := d
+			newD.Text = dText[:pos+1]
+			results = append(results, newD)
+
+			dText = dText[pos+1:]
+		}
+
+		if dText != "" {
+			newD := d
+			newD.Text = dText
+			results = append(results, newD)
+		}
+	}
+
+	return results
+}
+
+// AddIndents inserts indent after each newline, as an equality.
+func AddIndents(diffs []Diff, indent string) []Diff {
+	newlined := false
+
+	results := make([]Diff, 0, len(diffs))
+
+	for _, d := range diffs {
+		dText := d.Text
+		if newlined {
+			newD := Diff{
+				Type: Equal,
+				Text: indent,
+			}
+			results = append(results, newD)
+		}
+
+		results = append(results, d)
+
+		newlined = strings.Contains(dText, "\n")
+	}
+
+	return results
+}
+
+// Split splits diffs into spans of whitespace runs and others.
+func Split(diffs []Diff) []Span {
+	results := make([]Span, 0, len(diffs))
+
+	for _, d := range diffs {
+		if len(d.Text) == 0 {
+			continue
+		}
+
+		var prevSpace bool
+		var s []rune
+		for i, r := range d.Text {
+			space := !unicode.IsPrint(r) || unicode.IsSpace(r)
+			if i == 0 {
+				prevSpace = space
+			}
+
+			if space != prevSpace {
+				newD := d
+				newD.Text = string(s)
+				results = append(results, Span{
+					Diff:    newD,
+					IsSpace: prevSpace,
+				})
+				s = s[:0]
+			}
+			s = append(s, r)
+			prevSpace = space
+		}
+		if len(s) > 0 {
+			newD := d
+			newD.Text = string(s)
+			results = append(results, Span{
+				Diff:    newD,
+				IsSpace: prevSpace,
+			})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,154 @@
+package diff
+
+import (
+	"html"
+	"io"
+	"strings"
+)
+
+// Renderer writes diffs to w.
+type Renderer interface {
+	Render(w io.Writer, diffs []Diff) error
+}
+
+// TextRenderer renders diffs inline without any escape sequences.
+// Empty markers are defaulted to `git diff --word-diff=plain` style: [-deleted-]{+inserted+}
+type TextRenderer struct {
+	DeleteStart, DeleteEnd string
+	InsertStart, InsertEnd string
+}
+
+func (r TextRenderer) Render(w io.Writer, diffs []Diff) error {
+	ds, de := orDefault(r.DeleteStart, "[-"), orDefault(r.DeleteEnd, "-]")
+	is, ie := orDefault(r.InsertStart, "{+"), orDefault(r.InsertEnd, "+}")
+
+	sb := &strings.Builder{}
+	for _, d := range diffs {
+		switch d.Type {
+		case Delete:
+			sb.WriteString(ds + d.Text + de)
+		case Insert:
+			sb.WriteString(is + d.Text + ie)
+		default:
+			sb.WriteString(d.Text)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Printer prints decorated text, such as *color.Color of github.com/fatih/color.
+type Printer interface {
+	Fprint(w io.Writer, a ...interface{}) (n int, err error)
+}
+
+// ANSIRenderer renders diffs decorated by Printers.
+// Whitespace runs in edits are printed by InsertSpace and DeleteSpace.
+type ANSIRenderer struct {
+	Insert, InsertSpace Printer
+	Delete, DeleteSpace Printer
+}
+
+func (r ANSIRenderer) Render(w io.Writer, diffs []Diff) error {
+	for _, s := range Split(diffs) {
+		var p Printer
+		switch s.Type {
+		case Delete:
+			p = r.Delete
+			if s.IsSpace {
+				p = r.DeleteSpace
+			}
+		case Insert:
+			p = r.Insert
+			if s.IsSpace {
+				p = r.InsertSpace
+			}
+		}
+
+		var err error
+		if p == nil {
+			_, err = io.WriteString(w, s.Text)
+		} else {
+			_, err = p.Fprint(w, s.Text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HTMLRenderer renders diffs as HTML fragments.
+// Deletions are in <del>, insertions are in <ins>, and their whitespace runs have class="space".
+type HTMLRenderer struct{}
+
+func (r HTMLRenderer) Render(w io.Writer, diffs []Diff) error {
+	sb := &strings.Builder{}
+	for _, s := range Split(SplitByNewline(diffs)) {
+		text := html.EscapeString(s.Text)
+
+		var tag string
+		switch s.Type {
+		case Delete:
+			tag = "del"
+		case Insert:
+			tag = "ins"
+		default:
+			sb.WriteString(text)
+			continue
+		}
+
+		sb.WriteString("<" + tag)
+		if s.IsSpace {
+			sb.WriteString(` class="space"`)
+		}
+		sb.WriteString(">" + text + "</" + tag + ">")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// LineRenderer renders line diffs (Compute with Line) as lines prefixed with "- " (deleted), "+ " (inserted) or "  ".
+// Every line ends with a newline.
+type LineRenderer struct{}
+
+func (r LineRenderer) Render(w io.Writer, diffs []Diff) error {
+	sb := &strings.Builder{}
+	for _, d := range diffs {
+		prefix := "  "
+		switch d.Type {
+		case Delete:
+			prefix = "- "
+		case Insert:
+			prefix = "+ "
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			sb.WriteString(prefix + line)
+			if !strings.HasSuffix(line, "\n") {
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// RenderString renders diffs by r into a string.
+func RenderString(r Renderer, diffs []Diff) string {
+	sb := &strings.Builder{}
+	r.Render(sb, diffs) // strings.Builder never fails
+	return sb.String()
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
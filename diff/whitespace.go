@@ -0,0 +1,202 @@
+package diff
+
+import (
+	"strings"
+)
+
+// NormalizeEOL replaces CRLF and CR with LF.
+func NormalizeEOL(s string) string {
+	s, _ = normalizeEOL(s)
+	return s
+}
+
+// NormalizeSpace trims trailing whitespaces of each line and collapses whitespace runs into a space.
+func NormalizeSpace(s string) string {
+	s, _ = normalizeSpace(s)
+	return s
+}
+
+// Denormalize maps diffs between normalized got and want (by NormalizeEOL and/or NormalizeSpace)
+// back onto the original got and want, so that what is printed is what was given.
+//
+// Edits are the same in both results, but equalities are taken from got in gotDiffs and from want in wantDiffs,
+// since they may differ in ignored whitespaces.
+func Denormalize(diffs []Diff, got, want string, eol, space bool) (gotDiffs, wantDiffs []Diff) {
+	_, gpos := normalize(got, eol, space)
+	_, wpos := normalize(want, eol, space)
+
+	var gi, wi int
+	for _, d := range diffs {
+		n := len(d.Text)
+		switch d.Type {
+		case Equal:
+			gotDiffs = append(gotDiffs, Diff{Type: Equal, Text: got[gpos[gi]:gpos[gi+n]]})
+			wantDiffs = append(wantDiffs, Diff{Type: Equal, Text: want[wpos[wi]:wpos[wi+n]]})
+			gi += n
+			wi += n
+		case Delete:
+			text := got[gpos[gi]:gpos[gi+n]]
+			gotDiffs = append(gotDiffs, splitDropped(d, text, space)...)
+			wantDiffs = append(wantDiffs, Diff{Type: Delete, Text: text})
+			gi += n
+		case Insert:
+			text := want[wpos[wi]:wpos[wi+n]]
+			gotDiffs = append(gotDiffs, Diff{Type: Insert, Text: text})
+			wantDiffs = append(wantDiffs, splitDropped(d, text, space)...)
+			wi += n
+		}
+	}
+
+	// whitespaces normalized into nothing
+	if rest := got[gpos[gi]:]; rest != "" {
+		gotDiffs = append(gotDiffs, Diff{Type: Equal, Text: rest})
+	}
+	if rest := want[wpos[wi]:]; rest != "" {
+		wantDiffs = append(wantDiffs, Diff{Type: Equal, Text: rest})
+	}
+
+	return gotDiffs, wantDiffs
+}
+
+// splitDropped splits trailing whitespaces dropped by NormalizeSpace off the edit d of the original text,
+// as an equality.
+func splitDropped(d Diff, text string, space bool) []Diff {
+	if !space || d.Text == "" || isSpace(d.Text[len(d.Text)-1]) {
+		return []Diff{{Type: d.Type, Text: text}}
+	}
+
+	trimmed := strings.TrimRight(text, " \t\f\v")
+	if trimmed == text {
+		return []Diff{{Type: d.Type, Text: text}}
+	}
+	return []Diff{{Type: d.Type, Text: trimmed}, {Type: Equal, Text: text[len(trimmed):]}}
+}
+
+// normalize normalizes s, and returns positions in s of each byte of the result.
+// A part [i:j] of the result is made from s[pos[i]:pos[j]].
+func normalize(s string, eol, space bool) (string, []int) {
+	pos := make([]int, len(s)+1)
+	for i := range pos {
+		pos[i] = i
+	}
+
+	if eol {
+		var epos []int
+		s, epos = normalizeEOL(s)
+		pos = composePos(pos, epos)
+	}
+	if space {
+		var spos []int
+		s, spos = normalizeSpace(s)
+		pos = composePos(pos, spos)
+	}
+	return s, pos
+}
+
+func composePos(outer, inner []int) []int {
+	results := make([]int, len(inner))
+	for i, p := range inner {
+		results[i] = outer[p]
+	}
+	return results
+}
+
+func normalizeEOL(s string) (string, []int) {
+	sb := &strings.Builder{}
+	pos := make([]int, 0, len(s)+1)
+
+	for i := 0; i < len(s); i++ {
+		pos = append(pos, i)
+		if s[i] == '\r' {
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+			sb.WriteByte('\n')
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String(), append(pos, len(s))
+}
+
+func normalizeSpace(s string) (string, []int) {
+	sb := &strings.Builder{}
+	pos := make([]int, 0, len(s)+1)
+
+	for i := 0; i < len(s); i++ {
+		if !isSpace(s[i]) {
+			pos = append(pos, i)
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		j := i
+		for j < len(s) && isSpace(s[j]) {
+			j++
+		}
+		if j < len(s) && s[j] != '\n' {
+			pos = append(pos, i)
+			sb.WriteByte(' ')
+		}
+		// trailing ones are dropped
+		i = j - 1
+	}
+	pos = append(pos, len(s))
+	pos[0] = 0 // dropped ones at the beginning
+	return sb.String(), pos
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\f' || b == '\v'
+}
+
+// zeroWidths are invisible characters always made visible by Visualize.
+var zeroWidths = map[rune]string{
+	'\u200b': "<ZWSP>",
+	'\u200c': "<ZWNJ>",
+	'\u200d': "<ZWJ>",
+	'\u2060': "<WJ>",
+	'\ufeff': "<BOM>",
+}
+
+// Visualize renders invisible characters visibly.
+// Spaces and tabs are replaced only in edits (not in equalities), to keep the text readable.
+// CRs and zero-width characters are replaced everywhere.
+func Visualize(diffs []Diff) []Diff {
+	results := make([]Diff, 0, len(diffs))
+
+	for _, d := range diffs {
+		sb := &strings.Builder{}
+		for _, r := range d.Text {
+			if zw, found := zeroWidths[r]; found {
+				sb.WriteString(zw)
+				continue
+			}
+			if r == '\r' {
+				sb.WriteString("␍")
+				continue
+			}
+
+			if d.Type != Equal {
+				switch r {
+				case ' ':
+					sb.WriteString("·")
+					continue
+				case '\t':
+					sb.WriteString("→")
+					continue
+				case '\n':
+					sb.WriteString("↵\n")
+					continue
+				}
+			}
+			sb.WriteRune(r)
+		}
+
+		newD := d
+		newD.Text = sb.String()
+		results = append(results, newD)
+	}
+
+	return results
+}
@@ -0,0 +1,175 @@
+package gotwant
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"reflect"
+	"sort"
+)
+
+// explain finds why reflect.DeepEqual(got, want) is false, for got and want printed identically.
+// It returns a path and a reason, like `.F: non-nil funcs are never DeepEqual`, or "" if not found.
+// The path is rooted at "." for both got and want.
+//
+// Pointer aliasing is not reported; reflect.DeepEqual follows pointers, so it alone never makes them differ.
+func explain(got, want interface{}) string {
+	e := explainer{visited: make(map[explainKey]bool)}
+	return e.explain(".", reflect.ValueOf(got), reflect.ValueOf(want))
+}
+
+// subpath appends elem (".F", "[1]", ...) to path.
+func subpath(path, elem string) string {
+	if path == "." {
+		return elem
+	}
+	return path + elem
+}
+
+type explainKey struct {
+	got, want uintptr
+	typ       reflect.Type
+}
+
+type explainer struct {
+	visited map[explainKey]bool
+}
+
+func (e *explainer) explain(path string, g, w reflect.Value) string {
+	if !g.IsValid() || !w.IsValid() {
+		if g.IsValid() == w.IsValid() {
+			return ""
+		}
+		return path + ": got " + validString(g) + ", want " + validString(w)
+	}
+	if g.Type() != w.Type() {
+		return fmt.Sprintf("%s: got type %s, want type %s", path, typeString(g.Type()), typeString(w.Type()))
+	}
+
+	switch g.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if g.IsNil() != w.IsNil() {
+			return path + ": got " + nilString(g) + ", want " + nilString(w)
+		}
+		if g.IsNil() || g.Pointer() == w.Pointer() && (g.Kind() != reflect.Slice || g.Len() == w.Len()) {
+			return ""
+		}
+		key := explainKey{got: g.Pointer(), want: w.Pointer(), typ: g.Type()}
+		if e.visited[key] {
+			return ""
+		}
+		e.visited[key] = true
+	}
+
+	switch g.Kind() {
+	case reflect.Pointer:
+		return e.explain(path, g.Elem(), w.Elem())
+
+	case reflect.Interface:
+		if g.IsNil() || w.IsNil() {
+			if g.IsNil() == w.IsNil() {
+				return ""
+			}
+			return path + ": got " + nilString(g) + ", want " + nilString(w)
+		}
+		return e.explain(path, g.Elem(), w.Elem())
+
+	case reflect.Struct:
+		for i := 0; i < g.NumField(); i++ {
+			f := g.Type().Field(i)
+			if r := e.explain(subpath(path, "."+f.Name), g.Field(i), w.Field(i)); r != "" {
+				if !f.IsExported() {
+					r += " (unexported field)"
+				}
+				return r
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if g.Len() != w.Len() {
+			return fmt.Sprintf("%s: got len %d, want len %d", path, g.Len(), w.Len())
+		}
+		for i := 0; i < g.Len(); i++ {
+			if r := e.explain(subpath(path, fmt.Sprintf("[%d]", i)), g.Index(i), w.Index(i)); r != "" {
+				return r
+			}
+		}
+
+	case reflect.Map:
+		if g.Len() != w.Len() {
+			return fmt.Sprintf("%s: got len %d, want len %d", path, g.Len(), w.Len())
+		}
+		keys := g.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			kpath := subpath(path, fmt.Sprintf("[%#v]", k))
+			wv := w.MapIndex(k)
+			if !wv.IsValid() {
+				if isNaN(k) {
+					return kpath + ": NaN keys never match"
+				}
+				return kpath + ": missing in want"
+			}
+			if r := e.explain(kpath, g.MapIndex(k), wv); r != "" {
+				return r
+			}
+		}
+
+	case reflect.Func:
+		if g.IsNil() && w.IsNil() {
+			return ""
+		}
+		return path + ": non-nil funcs are never DeepEqual"
+
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		if isNaN(g) || isNaN(w) {
+			return path + ": NaN != NaN"
+		}
+		if !g.Equal(w) {
+			return fmt.Sprintf("%s: got %v, want %v", path, g, w)
+		}
+
+	case reflect.Chan, reflect.UnsafePointer:
+		if g.Pointer() != w.Pointer() {
+			return fmt.Sprintf("%s: got %#x, want %#x (different %s)", path, g.Pointer(), w.Pointer(), g.Kind())
+		}
+
+	default:
+		if !g.Equal(w) {
+			return fmt.Sprintf("%s: got %#v, want %#v", path, g, w)
+		}
+	}
+	return ""
+}
+
+func isNaN(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return cmplx.IsNaN(v.Complex())
+	default:
+		return false
+	}
+}
+
+func nilString(v reflect.Value) string {
+	if v.IsNil() {
+		return "nil"
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		if v.Len() == 0 {
+			return "empty"
+		}
+	}
+	return "non-nil"
+}
+
+func validString(v reflect.Value) string {
+	if v.IsValid() {
+		return "non-nil"
+	}
+	return "nil"
+}
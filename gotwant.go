@@ -2,13 +2,30 @@
 package gotwant
 
 import (
+	"cmp"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Defaults are read on every call, by package-level functions (Default) as well as cases.
+// Changing them affects all tests of the process; a Checker is scoped instead.
 var (
 	// FmtDefault is a default value of displaying contents of got/want.
 	FmtDefault = "%v"
+
+	// DiffDefault is a default value of appending a diff of got/want.
+	// It is initialized by an environment variable GOTWANT_DIFF (1, true, ...).
+	DiffDefault = envBool("GOTWANT_DIFF")
+
+	// UpdateDefault is a default value of the update mode of Dir cases.
+	// It is initialized by an environment variable GOTWANT_UPDATE (1, true, ...).
+	UpdateDefault = envBool("GOTWANT_UPDATE")
+
+	// TimeoutDefault is a default value of how long channel cases wait, and a grace period of NoLeaks.
+	TimeoutDefault = time.Second
 )
 
 // T has a few part of testing.T, to test gotwant itself.
@@ -42,18 +59,27 @@ func Desc(desc string) Option {
 	}
 }
 
+// Diff enables(disables) a plain-text diff of got and want, appended below them. default: DiffDefault
+func Diff(enabled bool) Option {
+	return func(c TestCase) {
+		if d, ok := c.(interface{ SetDiff(bool) }); ok {
+			d.SetDiff(enabled)
+		}
+	}
+}
+
 // Test if for a single try.
 func Test(t T, got, want interface{}, opts ...Option) {
 	t.Helper()
 
-	Case(got, want, opts...).Test(t)
+	Default.Test(t, got, want, opts...)
 }
 
 // TestExpr tests got == expr (boolean comparison)
 func TestExpr(t T, got interface{}, expr bool, opts ...Option) {
 	t.Helper()
 
-	ExprCase(got, expr, opts...).Test(t)
+	Default.TestExpr(t, got, expr, opts...)
 }
 
 // TestError tests given error (got) is (1) exactly the error you wanted or (2) its message matches your pattern.
@@ -62,7 +88,7 @@ func TestExpr(t T, got interface{}, expr bool, opts ...Option) {
 func TestError(t T, got error, want interface{}, opts ...Option) {
 	t.Helper()
 
-	Error(got, want, opts...).Test(t)
+	Default.TestError(t, got, want, opts...)
 }
 
 // TestPanic tests function got panic(want) or not.
@@ -70,7 +96,206 @@ func TestError(t T, got error, want interface{}, opts ...Option) {
 func TestPanic(t T, got func(), want interface{}, opts ...Option) {
 	t.Helper()
 
-	Panic(got, want, opts...).Test(t)
+	Default.TestPanic(t, got, want, opts...)
+}
+
+// TestJSON tests got and want are semantically the same JSON documents.
+// See JSON for acceptable types of got and want.
+func TestJSON(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	JSON(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestText tests got and want are the same texts, line by line.
+func TestText(t T, got, want string, opts ...Option) {
+	t.Helper()
+
+	Text(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestDocument tests got and want are semantically the same documents, decoded by unmarshal.
+// See Document for acceptable types of got and want.
+func TestDocument(t T, got, want interface{}, unmarshal Unmarshaler, opts ...Option) {
+	t.Helper()
+
+	Document(got, want, unmarshal, Default.Options(opts...)...).Test(t)
+}
+
+// TestXML tests got and want are semantically the same XML documents.
+// See XML for acceptable types of got and want.
+func TestXML(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	XML(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestContains tests got contains want.
+// See Contains for acceptable types of got.
+func TestContains(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	Contains(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestElementsMatch tests got and want have the same elements regardless of order.
+func TestElementsMatch(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	ElementsMatch(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestSubset tests all elements of got are in want.
+func TestSubset(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	Subset(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestKeysEqual tests a map got has the same keys as want (a map or a slice of keys).
+func TestKeysEqual(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	KeysEqual(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestLen tests the length of got.
+func TestLen(t T, got interface{}, want int, opts ...Option) {
+	t.Helper()
+
+	Len(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestEmpty tests got is empty.
+func TestEmpty(t T, got interface{}, opts ...Option) {
+	t.Helper()
+
+	Empty(got, Default.Options(opts...)...).Test(t)
+}
+
+// TestGreater tests got > bound.
+func TestGreater[N cmp.Ordered](t T, got, bound N, opts ...Option) {
+	t.Helper()
+
+	Greater(got, bound, Default.Options(opts...)...).Test(t)
+}
+
+// TestLess tests got < bound.
+func TestLess[N cmp.Ordered](t T, got, bound N, opts ...Option) {
+	t.Helper()
+
+	Less(got, bound, Default.Options(opts...)...).Test(t)
+}
+
+// TestBetween tests low <= got <= high.
+func TestBetween[N cmp.Ordered](t T, got, low, high N, opts ...Option) {
+	t.Helper()
+
+	Between(got, low, high, Default.Options(opts...)...).Test(t)
+}
+
+// TestSorted tests got is sorted by less.
+func TestSorted[E any](t T, got []E, less func(a, b E) bool, opts ...Option) {
+	t.Helper()
+
+	Sorted(got, less, Default.Options(opts...)...).Test(t)
+}
+
+// TestMonotonic tests got is non-decreasing or non-increasing.
+func TestMonotonic[N cmp.Ordered](t T, got []N, opts ...Option) {
+	t.Helper()
+
+	Monotonic(got, Default.Options(opts...)...).Test(t)
+}
+
+// TestReceive tests want is received from ch within the timeout.
+func TestReceive[E any](t T, ch <-chan E, want E, opts ...Option) {
+	t.Helper()
+
+	Receive(ch, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestNotReceive tests nothing is received from ch within the timeout.
+func TestNotReceive[E any](t T, ch <-chan E, opts ...Option) {
+	t.Helper()
+
+	NotReceive(ch, Default.Options(opts...)...).Test(t)
+}
+
+// TestClosed tests ch is closed within the timeout.
+func TestClosed[E any](t T, ch <-chan E, opts ...Option) {
+	t.Helper()
+
+	Closed(ch, Default.Options(opts...)...).Test(t)
+}
+
+// TestDrain tests values received from ch until closed within the timeout are want.
+func TestDrain[E any](t T, ch <-chan E, want []E, opts ...Option) {
+	t.Helper()
+
+	Drain(ch, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestHTTP tests a HTTP response got is what you expected.
+// See HTTP for acceptable types of got.
+func TestHTTP(t T, got interface{}, want Response, opts ...Option) {
+	t.Helper()
+
+	HTTP(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestDir tests directories (paths or fs.FS) got and want have the same files.
+// See Dir for the update mode.
+func TestDir(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	Dir(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestBytes tests got and want are the same bytes.
+// See Bytes for acceptable types of got and want.
+func TestBytes(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	Bytes(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestIsType tests got has the same dynamic type as want.
+func TestIsType(t T, got, want interface{}, opts ...Option) {
+	t.Helper()
+
+	IsType(got, want, Default.Options(opts...)...).Test(t)
+}
+
+// TestImplements tests got implements an interface iface points, such as (*io.Closer)(nil).
+func TestImplements(t T, got, iface interface{}, opts ...Option) {
+	t.Helper()
+
+	Implements(got, iface, Default.Options(opts...)...).Test(t)
+}
+
+// TestAsType tests got is a V (or an error wrapping a V), and returns it.
+func TestAsType[V any](t T, got interface{}, opts ...Option) (V, bool) {
+	t.Helper()
+
+	AsType[V](got, Default.Options(opts...)...).Test(t)
+	return asType[V](got)
+}
+
+// TestNil tests got is nil.
+// See Nil for interfaces holding nil pointers.
+func TestNil[V any](t T, got V, opts ...Option) {
+	t.Helper()
+
+	Nil(got, Default.Options(opts...)...).Test(t)
+}
+
+// TestNotNil tests got is not nil.
+// See NotNil for interfaces holding nil pointers.
+func TestNotNil[V any](t T, got V, opts ...Option) {
+	t.Helper()
+
+	NotNil(got, Default.Options(opts...)...).Test(t)
 }
 
 // TestAll is for a series of tries(Cases).
@@ -83,7 +308,7 @@ func TestAll(t T, cases []TestCase) {
 }
 
 func stringify(s interface{}) *string {
-	if s == nil {
+	if _, ok := typedNil(s); s == nil || ok {
 		return nil
 	}
 
@@ -99,6 +324,11 @@ func stringify(s interface{}) *string {
 	return nil
 }
 
+func envBool(name string) bool {
+	b, _ := strconv.ParseBool(os.Getenv(name))
+	return b
+}
+
 func indent(s string) string {
 	return strings.ReplaceAll(s, "\n", "\n      ")
 }
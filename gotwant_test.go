@@ -2,10 +2,21 @@ package gotwant_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/shu-go/gotwant"
 )
@@ -26,6 +37,24 @@ func (t *testerT) Errorf(format string, args ...interface{}) {
 	fmt.Fprintf(&t.buf, format, args...)
 }
 
+// testCase tests c by tt, and checks its failure message matches pattern ("": passes).
+func testCase(t *testing.T, tt *testerT, c gotwant.TestCase, pattern string) {
+	t.Helper()
+
+	tt.Reset()
+	c.Test(tt)
+	r := tt.buf.String()
+	if pattern == "" {
+		if r != "" {
+			t.Error(r)
+		}
+		return
+	}
+	if !regexp.MustCompile(pattern).MatchString(r) {
+		t.Error(r)
+	}
+}
+
 func TestCase(t *testing.T) {
 	tt := &testerT{buf: bytes.Buffer{}}
 
@@ -185,6 +214,900 @@ func TestOption(t *testing.T) {
 	}
 }
 
+func TestDiff(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	c := gotwant.Case("the quick fox", "the quack fox", gotwant.Diff(true))
+	c.Test(tt)
+	r := tt.buf.String()
+	if !regexp.MustCompile(`\s*got:  the quick fox\s*want: the quack fox\s*diff: the \[-quick-\]\{\+quack\+\} fox`).MatchString(r) {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.Case("the quick fox", "the quack fox", gotwant.Diff(false))
+	c.Test(tt)
+	r = tt.buf.String()
+	if regexp.MustCompile(`diff:`).MatchString(r) {
+		t.Error(r)
+	}
+}
+
+func TestPretty(t *testing.T) {
+	type node struct {
+		Name  string
+		Tags  map[string]int
+		Items []string
+		Next  *node
+	}
+
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	n := &node{Name: "a", Tags: map[string]int{"z": 1, "b": 2}, Items: []string{"x", "x", "x"}}
+	n.Next = n
+	c := gotwant.Case(n, &node{Name: "b"}, gotwant.Format(gotwant.FmtPretty))
+	c.Test(tt)
+	r := tt.buf.String()
+	want := `
+got:  #1 &gotwant_test.node{
+          Name: "a",
+          Tags: map[string]int{
+              "b": 2,
+              "z": 1,
+          },
+          Items: []string{"x", "x", "x"},
+          Next: <cycle #1>,
+      }
+want: &gotwant_test.node{
+          Name: "b",
+          Tags: map[string]int(nil),
+          Items: []string(nil),
+          Next: (*gotwant_test.node)(nil),
+      }`
+	if r != want {
+		t.Error(r)
+	}
+
+	// long values are printed in pretty format by default
+	tt.Reset()
+	long := make([]node, 6)
+	c = gotwant.Case(long, long[:5])
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`got:  \[\]gotwant_test.node\{\n\s+gotwant_test.node\{\n[^}]*},\n\s+// ... repeated 5 more time\(s\)`).MatchString(r) {
+		t.Error(r)
+	}
+}
+
+func TestPrettyPointer(t *testing.T) {
+	type leaf struct {
+		V int
+	}
+	type tree struct {
+		L, R *leaf
+		M    map[string]int
+	}
+
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	shared := &leaf{V: 1}
+	c := gotwant.Case(tree{L: shared, R: shared, M: map[string]int{"b": 1, "a": 2}}, tree{L: &leaf{V: 1}, R: &leaf{V: 2}})
+	c.Test(tt)
+	r := tt.buf.String()
+	want := `
+got:  gotwant_test.tree{
+          L: #1 &gotwant_test.leaf{
+              V: 1,
+          },
+          R: <same as #1>,
+          M: map[string]int{
+              "a": 2,
+              "b": 1,
+          },
+      }
+want: gotwant_test.tree{
+          L: &gotwant_test.leaf{
+              V: 1,
+          },
+          R: &gotwant_test.leaf{
+              V: 2,
+          },
+          M: map[string]int(nil),
+      }`
+	if r != want {
+		t.Error(r)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	c := gotwant.JSON(`{"b": [1, 2.0], "a": "x"}`, []byte(`{"a":"x","b":[1,2]}`))
+	c.Test(tt)
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.JSON(`{"a": "x", "b": [1, 3], "c": true, "d": {"id": 1}}`, map[string]interface{}{"a": "y", "b": []int{1}, "e": nil, "d": map[string]int{"id": 2}})
+	c.Test(tt)
+	r = tt.buf.String()
+	want := `
+$.a: got "x", want "y"
+$.b[1]: unexpected (got 3)
+$.d.id: got 1, want 2
+$.e: missing (want null)
+$.c: unexpected (got true)
+got:  {
+        "a": "x",
+        "b": [
+          1,
+          3
+        ],
+        "c": true,
+        "d": {
+          "id": 1
+        }
+      }
+want: {
+        "a": "y",
+        "b": [
+          1
+        ],
+        "d": {
+          "id": 2
+        },
+        "e": null
+      }`
+	if r != want {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.JSON(`{"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}], "time": "now"}`,
+		`{"items": [{"id": 9, "name": "a"}, {"id": 8, "name": "b"}]}`,
+		gotwant.IgnorePaths("$.items[*].id"), gotwant.AllowExtra())
+	c.Test(tt)
+	r = tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.JSON(`{"id": 9007199254740993, "f": 1.50, "e": 1e2}`, `{"id": 9007199254740992, "f": 1.5, "e": 100}`)
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`^\n\$\.id: got 9007199254740993, want 9007199254740992\ngot:  `).MatchString(r) {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.JSON(`{"a": `, `{}`)
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`got invalid JSON`).MatchString(r) {
+		t.Error(r)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	// a custom unmarshaler, decoding numbers as float64 and keys of map[interface{}]interface{}
+	unmarshal := func(data []byte, v interface{}) error {
+		var m map[string]interface{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		kv := make(map[interface{}]interface{})
+		for k, v := range m {
+			kv[k] = v
+		}
+		*(v.(*interface{})) = kv
+		return nil
+	}
+
+	c := gotwant.Document(`{"b": 1.0, "a": ["x"]}`, map[string]interface{}{"a": []string{"x"}, "b": 1}, unmarshal)
+	c.Test(tt)
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.Document(`{"b": 2, "a": ["x"]}`, `{"a": ["y"], "b": 2}`, unmarshal)
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`^\n\$\.a\[0\]: got "x", want "y"\ngot:  `).MatchString(r) {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.Document(`{"a": `, `{}`, unmarshal)
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`got invalid document`).MatchString(r) {
+		t.Error(r)
+	}
+}
+
+func TestXML(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	c := gotwant.XML(`<?xml version="1.0"?>
+<config version="2" name="a">
+  <!-- servers -->
+  <server port="80">web</server>
+  <user>root</user>
+</config>`, `<config name="a" version="2"><user> root </user><server port="80">web</server></config>`)
+	c.Test(tt)
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	// an element is indexed even if only one
+	tt.Reset()
+	c = gotwant.XML(`<config><server port="80" id="1"/></config>`, `<config><server port="80" id="2"/></config>`,
+		gotwant.IgnorePaths(`$.config.server[*]["@id"]`))
+	c.Test(tt)
+	r = tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	// namespaces
+	tt.Reset()
+	c = gotwant.XML(`<r xmlns:a="urn:a" xmlns:b="urn:b"><a:x>1</a:x><b:x>2</b:x></r>`, `<r xmlns:a="urn:a" xmlns:b="urn:b"><a:x>2</a:x><b:x>1</b:x></r>`)
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`^\n\$\.r\["\{urn:a\}x"\]\[0\]: got "1", want "2"\n\$\.r\["\{urn:b\}x"\]\[0\]: got "2", want "1"\n`).MatchString(r) {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.XML(`<config><server port="80"/><server port="8080"/></config>`, `<config><server port="80"/><server port="8081"/></config>`)
+	c.Test(tt)
+	r = tt.buf.String()
+	want := `
+$.config.server[1]["@port"]: got "8080", want "8081"
+got:  <config>
+        <server port="80"/>
+        <server port="8080"/>
+      </config>
+want: <config>
+        <server port="80"/>
+        <server port="8081"/>
+      </config>`
+	if r != want {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.XML(`<a></b>`, `<a/>`)
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`got invalid XML`).MatchString(r) {
+		t.Error(r)
+	}
+}
+
+func TestText(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	c := gotwant.Text("Usage: hoge  \r\n\r\n  -h  help\r\n", `
+		Usage: hoge
+		  -h  help
+		`, gotwant.TrimTrailingSpace(), gotwant.NormalizeEOL(), gotwant.Dedent(), gotwant.IgnoreBlankLines())
+	c.Test(tt)
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	c = gotwant.Text("a\n\nb\nc\nd\ne\nf\ng\nh\ni\nj ", "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk", gotwant.IgnoreBlankLines())
+	c.Test(tt)
+	r = tt.buf.String()
+	want := `
+line 3 (want line 2): got "b", want "B"
+line 11 (want line 10): got "j ", want "j"
+want line 11: missing "k"
+got:   1| a
+       3| b
+       4| c
+       5| d
+       6| e
+       7| f
+       8| g
+       9| h
+      10| i
+      11| j 
+want:  1| a
+       2| B
+       3| c
+       4| d
+       5| e
+       6| f
+       7| g
+       8| h
+       9| i
+      10| j
+      11| k`
+	if r != want {
+		t.Error(r)
+	}
+
+	// lines are aligned, not compared by index
+	tt.Reset()
+	c = gotwant.Text("a\nb\nc\nd", "a\nx\nb\nc\nD")
+	c.Test(tt)
+	r = tt.buf.String()
+	if !regexp.MustCompile(`^\nwant line 2: missing "x"\nline 4 \(want line 5\): got "d", want "D"\ngot:  `).MatchString(r) {
+		t.Error(r)
+	}
+}
+
+func TestCollection(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	gotwant.TestAll(tt, []gotwant.TestCase{
+		gotwant.Contains([]int{1, 2, 3}, 2),
+		gotwant.Contains([2]string{"a", "b"}, "b"),
+		gotwant.Contains(map[string]int{"a": 1}, "a"),
+		gotwant.Contains("hello", "ell"),
+		gotwant.Contains("hello", 'e'),
+		gotwant.ElementsMatch([]int{1, 2, 2, 3}, []int{3, 2, 1, 2}),
+		gotwant.ElementsMatch("abc", "cab"),
+		gotwant.ElementsMatch("ab", []string{"b", "a"}),
+		gotwant.Subset([]int{1, 1, 3}, []int{1, 2, 3}),
+		gotwant.KeysEqual(map[string]int{"a": 1, "b": 2}, map[string]bool{"b": true, "a": false}),
+		gotwant.KeysEqual(map[string]int{"a": 1, "b": 2}, []string{"b", "a"}),
+		gotwant.Len([]int{1, 2}, 2),
+		gotwant.Len("abc", 3),
+		gotwant.Len(nil, 0),
+		gotwant.Empty(nil),
+		gotwant.Empty([]int{}),
+		gotwant.Empty(map[string]int(nil)),
+		gotwant.Empty(0),
+		gotwant.Empty((*int)(nil)),
+	})
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	testCase(t, tt, gotwant.Contains([]int{1, 2, 3}, 4), `missing: 4\s*got:  \[1 2 3\]$`)
+	testCase(t, tt, gotwant.Contains("hello", "world"), `missing: world\s*got:  hello$`)
+	testCase(t, tt, gotwant.Contains("hello", 'x', gotwant.Format("%q")), `missing: 'x'\s*got:  "hello"$`)
+	testCase(t, tt, gotwant.ElementsMatch("ab", "ac"), `missing: \[c\]\s*extra:   \[b\]`)
+	testCase(t, tt, gotwant.ElementsMatch([]int{1, 2, 2, 5}, []int{4, 2, 1, 3}), `missing: \[4 3\]\s*extra:   \[2 5\]\s*got:  \[1 2 2 5\]\s*want: \[4 2 1 3\]`)
+	testCase(t, tt, gotwant.Subset([]string{"a", "x"}, []string{"a", "b"}), `extra:   \[x\]\s*got:  \[a x\]\s*want: \[a b\]`)
+	testCase(t, tt, gotwant.KeysEqual(map[string]int{"a": 1, "c": 3}, []string{"a", "b"}), `missing: \[b\]\s*extra:   \[c\]\s*got:  map\[a:1 c:3\]\s*want: \[a b\]`)
+	testCase(t, tt, gotwant.Len([]int{1, 2, 3}, 2), `len: got 3, want 2\s*got:  \[1 2 3\]`)
+	testCase(t, tt, gotwant.Empty(map[string]int{"a": 1}), `len: got 1, want 0\s*got:  map\[a:1\]`)
+	testCase(t, tt, gotwant.Empty(1), `not empty\s*got:  1`)
+	testCase(t, tt, gotwant.Contains(1, 1), `got int, not a collection`)
+}
+
+func TestOrder(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	byLen := func(a, b string) bool { return len(a) < len(b) }
+
+	gotwant.TestAll(tt, []gotwant.TestCase{
+		gotwant.Greater(6, 5),
+		gotwant.Less(1.5, 2),
+		gotwant.Between("b", "a", "c"),
+		gotwant.Between(5, 1, 5),
+		gotwant.Sorted([]string{"a", "bb", "cc", "ddd"}, byLen),
+		gotwant.Sorted([]string{}, byLen),
+		gotwant.Monotonic([]int{1, 1, 2, 3}),
+		gotwant.Monotonic([]int{3, 3, 2, 0}),
+	})
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	testCase(t, tt, gotwant.Greater(3, 5), `got:  3\s*want: > 5$`)
+	testCase(t, tt, gotwant.Less(5, 5), `got:  5\s*want: < 5$`)
+	testCase(t, tt, gotwant.Between(0.5, 1, 2, gotwant.Format("%.1f")), `got:  0.5\s*want: >= 1.0 && <= 2.0$`)
+	testCase(t, tt, gotwant.Sorted([]string{"a", "ccc", "bb", "d"}, byLen), `not sorted at \[1\], \[2\]: ccc, bb\s*got:  \[a ccc bb d\]`)
+	testCase(t, tt, gotwant.Monotonic([]int{3, 3, 2, 4, 1}), `not monotonic at \[2\], \[3\]: 2, 4\s*got:  \[3 3 2 4 1\]`)
+}
+
+func TestChan(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	timeout := gotwant.Timeout(10 * time.Millisecond)
+
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	gotwant.TestAll(tt, []gotwant.TestCase{
+		gotwant.Receive(ch, 1, timeout),
+		gotwant.Receive(ch, 2, timeout),
+		gotwant.NotReceive(ch, timeout),
+	})
+	ch <- 3
+	close(ch)
+	gotwant.Drain(ch, []int{3}, timeout).Test(tt)
+	gotwant.Closed(ch, timeout).Test(tt)
+	gotwant.Drain(ch, nil, timeout).Test(tt)
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	ch = make(chan int, 3)
+	testCase(t, tt, gotwant.Receive(ch, 1, timeout), `got NO value in 10ms\.\s*want: 1$`)
+
+	ch <- 2
+	testCase(t, tt, gotwant.Receive(ch, 1, timeout), `got:  2\s*want: 1$`)
+
+	ch <- 2
+	testCase(t, tt, gotwant.NotReceive(ch, timeout), `got a value, want NO value in 10ms\.\s*got:  2$`)
+
+	testCase(t, tt, gotwant.Closed(ch, timeout), `got NOT closed in 10ms\.$`)
+
+	ch <- 1
+	ch <- 2
+	testCase(t, tt, gotwant.Drain(ch, []int{1, 3}, timeout), `got NOT closed in 10ms\.\s*got:  \[1 2\]\s*want: \[1 3\]$`)
+
+	ch <- 1
+	close(ch)
+	testCase(t, tt, gotwant.Drain(ch, []int{1, 3}, timeout), `^\ngot:  \[1\]\s*want: \[1 3\]$`)
+	testCase(t, tt, gotwant.Receive(ch, 1, timeout), `got closed\.\s*want: 1$`)
+}
+
+func TestNoLeaks(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	done := make(chan struct{})
+	c := gotwant.NoLeaksCase(gotwant.Timeout(20 * time.Millisecond))
+	go func() {
+		<-done
+	}()
+	c.Test(tt)
+	r := tt.buf.String()
+	if !regexp.MustCompile(`(?s)^\n1 leaked goroutine\(s\)\n      goroutine \d+ \[chan receive\]:\n.*created by github.com/shu-go/gotwant_test.TestNoLeaks`).MatchString(r) {
+		t.Error(r)
+	}
+
+	tt.Reset()
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		close(done)
+	}()
+	c.Test(tt)
+	r = tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	// goroutines of other tests (such as parallel ones) are not reported
+	other := make(chan gotwant.TestCase, 1)
+	go func() {
+		other <- gotwant.NoLeaksCase(gotwant.Timeout(20 * time.Millisecond))
+	}()
+	oc := <-other
+	stop := make(chan struct{})
+	go func() {
+		<-stop
+	}()
+	testCase(t, tt, oc, "")
+
+	// descendants are reported
+	c = gotwant.NoLeaksCase(gotwant.Timeout(20 * time.Millisecond))
+	started := make(chan struct{})
+	go func() {
+		go func() {
+			<-stop
+		}()
+		close(started)
+		<-stop
+	}()
+	<-started
+	testCase(t, tt, c, `^\n2 leaked goroutine\(s\)\n`)
+	close(stop)
+
+	t.Run("Cleanup", func(t *testing.T) {
+		gotwant.NoLeaks(t)
+
+		done := make(chan struct{})
+		go func() {
+			<-done
+		}()
+		close(done)
+	})
+
+	tt.Reset()
+	gotwant.NoLeaks(tt)
+	r = tt.buf.String()
+	if r != "\nNoLeaks: *gotwant_test.testerT has no Cleanup" {
+		t.Error(r)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "application/json")
+	rec.Header().Set("X-Request-Id", "abc")
+	rec.WriteHeader(http.StatusOK)
+	rec.WriteString(`{"id": 1, "name": "hoge", "createdAt": "now"}`)
+
+	testCase(t, tt, gotwant.HTTP(rec, gotwant.Response{
+		Status: http.StatusOK,
+		Header: http.Header{"content-type": {"application/json"}},
+		JSON:   `{"id": 1, "name": "hoge"}`,
+	}, gotwant.AllowExtra()), "")
+
+	testCase(t, tt, gotwant.HTTP(rec, gotwant.Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"text/plain"}, "Location": {"/hoge/1"}},
+		JSON:   `{"id": 2, "name": "hoge"}`,
+	}, gotwant.IgnorePaths("$.createdAt")), `^
+status: got 200 OK, want 201 Created
+header Content-Type: got "application/json", want "text/plain"
+header Location: missing \(want "/hoge/1"\)
+body \$\.id: got 1, want 2
+got:  \{\s*"createdAt": "now",\s*"id": 1,\s*"name": "hoge"\s*\}
+want: \{\s*"id": 2,\s*"name": "hoge"\s*\}$`)
+
+	resp := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       io.NopCloser(strings.NewReader("not found\n")),
+	}
+	testCase(t, tt, gotwant.HTTP(resp, gotwant.Response{Status: http.StatusNotFound, Body: "not found"}),
+		`body: differs at offset 9\s*got:  not found\n\s*want: not found$`)
+	b, _ := io.ReadAll(resp.Body)
+	gotwant.Test(t, string(b), "not found\n", gotwant.Desc("body is readable again"))
+}
+
+func TestDir(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	got := fstest.MapFS{
+		"a.txt":       {Data: []byte("a\nb\nc\n")},
+		"sub/b.txt":   {Data: []byte("b")},
+		"sub/c.txt":   {Data: []byte("c\n")},
+		"extra.txt":   {Data: []byte("x")},
+		"image.bin":   {Data: []byte{0xff, 0x00}},
+		"noeol.txt":   {Data: []byte("eol")},
+		"same/d.txt":  {Data: []byte("d")},
+		"empty":       {Mode: fs.ModeDir},
+		"sub/e/f.txt": {Data: []byte("f")},
+	}
+	want := fstest.MapFS{
+		"a.txt":       {Data: []byte("a\nB\nc\n")},
+		"sub/b.txt":   {Data: []byte("b")},
+		"missing.txt": {Data: []byte("m")},
+		"image.bin":   {Data: []byte{0xff, 0x01}},
+		"noeol.txt":   {Data: []byte("eol\n")},
+		"same/d.txt":  {Data: []byte("d")},
+		"sub/c.txt":   {Data: []byte("c\n")},
+		"sub/e/f.txt": {Data: []byte("f")},
+	}
+
+	testCase(t, tt, gotwant.Dir(got, want), `^
+missing: missing\.txt
+extra:   extra\.txt
+a\.txt:
+        a
+      - b
+      \+ B
+        c
+image\.bin: binary files differ \(got 2 bytes, want 2 bytes\)
+noeol\.txt: differs in a newline at end of file$`)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "old.txt"), []byte("old"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".gitkeep"), nil, 0o644)
+	os.MkdirAll(filepath.Join(dir, "olddir", "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "olddir", "sub", "x.txt"), []byte("x"), 0o644)
+
+	// files not in got are kept
+	testCase(t, tt, gotwant.Dir(got, dir, gotwant.Update(true)), "")
+	testCase(t, tt, gotwant.Dir(got, dir), `^\nmissing: \.gitkeep\nmissing: old\.txt\nmissing: olddir/sub/x\.txt$`)
+
+	// unless pruned
+	testCase(t, tt, gotwant.Dir(got, dir, gotwant.Update(true), gotwant.Prune()), "")
+	testCase(t, tt, gotwant.Dir(got, dir), "")
+	testCase(t, tt, gotwant.Dir(dir, got), "")
+	if _, err := os.Stat(filepath.Join(dir, "olddir")); !os.IsNotExist(err) {
+		t.Errorf("olddir must be removed: %v", err)
+	}
+
+	testCase(t, tt, gotwant.Dir(dir, want, gotwant.Update(true)), `failed to update: want fstest.MapFS is not a path$`)
+}
+
+func TestBytes(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	gotwant.TestAll(tt, []gotwant.TestCase{
+		gotwant.Bytes(data, bytes.NewReader(data)),
+		gotwant.Bytes("abc", []byte("abc")),
+		gotwant.Bytes("", strings.NewReader("")),
+	})
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	testCase(t, tt, gotwant.Bytes(bytes.NewReader(data), append(append(data[:500:500], 'x'), data[501:]...)), "^"+regexp.QuoteMeta(`
+first difference at offset 500 (0x1f4)
+got:  000001e0  e0 e1 e2 e3 e4 e5 e6 e7  e8 e9 ea eb ec ed ee ef  |................|
+      000001f0  f0 f1 f2 f3 f4 f5 f6 f7  f8 f9 fa fb fc fd fe ff  |................|
+      00000200  00 01 02 03 04 05 06 07  08 09 0a 0b 0c 0d 0e 0f  |................|
+      00000210  10 11 12 13 14 15 16 17  18 19 1a 1b 1c 1d 1e 1f  |................|
+want: 000001e0  e0 e1 e2 e3 e4 e5 e6 e7  e8 e9 ea eb ec ed ee ef  |................|
+      000001f0  f0 f1 f2 f3 78 f5 f6 f7  f8 f9 fa fb fc fd fe ff  |....x...........|
+      00000200  00 01 02 03 04 05 06 07  08 09 0a 0b 0c 0d 0e 0f  |................|
+      00000210  10 11 12 13 14 15 16 17  18 19 1a 1b 1c 1d 1e 1f  |................|`)+"$")
+
+	testCase(t, tt, gotwant.Bytes("hello, world", "hello"), "^"+regexp.QuoteMeta(`
+want ended at offset 5 (0x5)
+got:  00000000  68 65 6c 6c 6f 2c 20 77  6f 72 6c 64              |hello, world|
+want: 00000000  68 65 6c 6c 6f                                    |hello|`)+"$")
+
+	testCase(t, tt, gotwant.Bytes("", "a"), `got ended at offset 0 \(0x0\)\s*got:  \(empty\)\s*want: 00000000  61 {48}\|a\|$`)
+}
+
+type myErr struct{ msg string }
+
+func (e *myErr) Error() string { return e.msg }
+
+type closer struct{}
+
+func (c *closer) Close() {}
+
+func TestType(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	var err error = &myErr{"hoge"}
+	wrapped := fmt.Errorf("wrapped: %w", err)
+
+	gotwant.TestAll(tt, []gotwant.TestCase{
+		gotwant.IsType(err, (*myErr)(nil)),
+		gotwant.IsType(1, 0),
+		gotwant.Implements(err, (*error)(nil)),
+		gotwant.Implements(&bytes.Buffer{}, (*io.Writer)(nil)),
+		gotwant.AsType[*myErr](err),
+		gotwant.AsType[*myErr](wrapped),
+		gotwant.AsType[fmt.Stringer](&bytes.Buffer{}),
+	})
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	e, ok := gotwant.TestAsType[*myErr](tt, wrapped)
+	if !ok || e.msg != "hoge" {
+		t.Error(e, ok)
+	}
+
+	testCase(t, tt, gotwant.IsType(wrapped, (*myErr)(nil)), `got type:  \*fmt\.wrapError\s*want type: \*github\.com/shu-go/gotwant_test\.myErr$`)
+	testCase(t, tt, gotwant.IsType([]*myErr{}, map[string][2]int{}), `got type:  \[\]\*github\.com/shu-go/gotwant_test\.myErr\s*want type: map\[string\]\[2\]int$`)
+	testCase(t, tt, gotwant.Implements(closer{}, (*io.Closer)(nil)), `got type github\.com/shu-go/gotwant_test\.closer does not implement io\.Closer \(method Close has pointer receiver\)$`)
+	testCase(t, tt, gotwant.Implements(&closer{}, (*io.Closer)(nil)), `got type \*github\.com/shu-go/gotwant_test\.closer does not implement io\.Closer \(wrong type for method Close: got func\(\), want func\(\) error\)$`)
+	testCase(t, tt, gotwant.Implements(1, (*io.Reader)(nil)), `got type int does not implement io\.Reader \(missing method Read\)$`)
+	testCase(t, tt, gotwant.AsType[int](errors.New("hoge")), `got type:  \*errors\.errorString\s*want type: int$`)
+	testCase(t, tt, gotwant.AsType[*myErr](errors.New("hoge")), `got type:  \*errors\.errorString\s*want type: \*github\.com/shu-go/gotwant_test\.myErr$`)
+}
+
+func TestNil(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	var p *myErr
+	var err error
+	var m map[string]int
+	var any interface{}
+
+	gotwant.TestAll(tt, []gotwant.TestCase{
+		gotwant.Nil(p),
+		gotwant.Nil(err),
+		gotwant.Nil(m),
+		gotwant.Nil(any),
+		gotwant.NotNil(&myErr{}),
+		gotwant.NotNil(error(&myErr{})),
+		gotwant.NotNil([]int{}),
+		gotwant.NotNil(0),
+		gotwant.Error(error(p), p),
+	})
+	r := tt.buf.String()
+	if r != "" {
+		t.Error(r)
+	}
+
+	err = p
+	any = m
+
+	testCase(t, tt, gotwant.Nil(err), `^\ngot non-nil error holding nil \*github\.com/shu-go/gotwant_test\.myErr\.$`)
+	testCase(t, tt, gotwant.NotNil(err), `^\ngot non-nil error holding nil \*github\.com/shu-go/gotwant_test\.myErr\.$`)
+	testCase(t, tt, gotwant.Nil(any), `^\ngot non-nil interface \{\} holding nil map\[string\]int\.$`)
+	testCase(t, tt, gotwant.Nil(&myErr{"hoge"}), `got:  hoge\s*want: nil$`)
+	testCase(t, tt, gotwant.NotNil(p), `^\ngot nil \*github\.com/shu-go/gotwant_test\.myErr\.$`)
+	testCase(t, tt, gotwant.Error(err, nil), `got non-nil error holding nil \*github\.com/shu-go/gotwant_test\.myErr\.\s*want error: <nil>$`)
+	testCase(t, tt, gotwant.Error(err, "hoge"), `got non-nil error holding nil \*github\.com/shu-go/gotwant_test\.myErr\.\s*want error: hoge$`)
+
+	// panics with typed nils and other values
+	testCase(t, tt, gotwant.Panic(func() { panic(p) }, "y"), `^\ngot panic with nil \*github\.com/shu-go/gotwant_test\.myErr\.\s*want error: y$`)
+	testCase(t, tt, gotwant.Panic(func() { panic(p) }, p), "")
+	testCase(t, tt, gotwant.Panic(func() { panic(42) }, "42"), "")
+	testCase(t, tt, gotwant.Panic(func() { panic(42) }, "y"), `got error:  42\s*want error: y$`)
+}
+
+// stringer prints s only.
+type stringer struct {
+	s  string
+	id int
+}
+
+func (s stringer) String() string   { return s.s }
+func (s stringer) GoString() string { return s.s }
+
+func TestExplain(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	type withFunc struct {
+		Name string
+		F    func()
+	}
+	type withFloat struct {
+		Values []float64
+	}
+	type withUnexported struct {
+		Name string
+		id   interface{}
+	}
+
+	f := func() {}
+	testCase(t, tt, gotwant.Case(withFunc{Name: "a", F: f}, withFunc{Name: "a", F: f}), `\n\s*reason: \.F: non-nil funcs are never DeepEqual$`)
+	testCase(t, tt, gotwant.Case(&withFloat{Values: []float64{1, math.NaN()}}, &withFloat{Values: []float64{1, math.NaN()}}), `\n\s*reason: \.Values\[1\]: NaN != NaN$`)
+	testCase(t, tt, gotwant.Case(map[float64]int{math.NaN(): 1}, map[float64]int{math.NaN(): 1}), `\n\s*reason: \[NaN\]: NaN keys never match$`)
+	testCase(t, tt, gotwant.Case([]interface{}{1, withUnexported{Name: "a", id: 1}}, []interface{}{1, withUnexported{Name: "a", id: int64(1)}}), `\n\s*reason: \[1\]\.id: got type int, want type int64 \(unexported field\)$`)
+	testCase(t, tt, gotwant.Case(stringer{s: "a", id: 1}, stringer{s: "a", id: 2}), `\n\s*reason: \.id: got 1, want 2 \(unexported field\)$`)
+	testCase(t, tt, gotwant.Case(math.NaN(), math.NaN()), `\n\s*reason: \.: NaN != NaN$`)
+
+	// aliasing alone is not a difference
+	x, y, z := 1, 1, 1
+	testCase(t, tt, gotwant.Case([]*int{&x, &x}, []*int{&y, &z}), "")
+}
+
+func TestChecker(t *testing.T) {
+	tt := &testerT{buf: bytes.Buffer{}}
+
+	t.Run("Fmt", func(t *testing.T) {
+		ch := gotwant.NewChecker()
+		ch.Fmt = "%#v"
+
+		testCase(t, tt, ch.Case("a", "b"), `^\ngot:  "a"\nwant: "b"$`)
+		testCase(t, tt, ch.Case("a", "b", gotwant.Format("%v")), `^\ngot:  a\nwant: b$`)
+		testCase(t, tt, ch.Error(errors.New("a"), "b"), `^\ngot error:  &errors\.errorString\{s:"a"\}\nwant error: "b"$`)
+
+		// package-level functions are not affected
+		testCase(t, tt, gotwant.Case("a", "b", gotwant.Default.Options()...), `^\ngot:  a\nwant: b$`)
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		ch := gotwant.NewChecker()
+		ch.Diff = true
+
+		testCase(t, tt, ch.Case("a b", "a c"), `\n\s*diff: a \[-b-\]\{\+c\+\}$`)
+		testCase(t, tt, ch.Case("a b", "a c", gotwant.Diff(false)), `^\ngot:  a b\nwant: a c$`)
+	})
+
+	t.Run("MaxLen", func(t *testing.T) {
+		ch := gotwant.NewChecker()
+		ch.MaxLen = 5
+
+		testCase(t, tt, ch.Case(strings.Repeat("a", 10), strings.Repeat("b", 3)), `^\ngot:  aaaaa\.\.\. \(5 more bytes\)\nwant: bbb$`)
+		testCase(t, tt, ch.ExprCase("あいう", false), `^\ngot:  あ\.\.\. \(6 more bytes\)$`)
+
+		// around the first difference
+		ch.MaxLen = 10
+		got := strings.Repeat("a ", 10) + "X" + strings.Repeat(" a", 10)
+		want := strings.Repeat("a ", 10) + "Y" + strings.Repeat(" a", 10)
+		testCase(t, tt, ch.Case(got, want), "^"+regexp.QuoteMeta("\n"+
+			"got:  (15 more bytes) ... a a X a a... (16 more bytes)\n"+
+			"want: (15 more bytes) ... a a Y a a... (16 more bytes)")+"$")
+		testCase(t, tt, ch.Error(errors.New(got), want), "^"+regexp.QuoteMeta("\n"+
+			"got error:  (15 more bytes) ... a a X a a... (16 more bytes)\n"+
+			"want error: (15 more bytes) ... a a Y a a... (16 more bytes)")+"$")
+
+		// the diff too
+		ch.Diff = true
+		testCase(t, tt, ch.Case(got, want), `\n\s*diff: `+regexp.QuoteMeta("(15 more bytes) ... a a [-X-]... (25 more bytes)")+"$")
+	})
+
+	t.Run("MaxLen of other cases", func(t *testing.T) {
+		ch := gotwant.NewChecker()
+		ch.MaxLen = 5
+		long := []int{1, 2, 3, 4, 5, 6}
+
+		testCase(t, tt, gotwant.Nil(long, ch.Options()...), `^\ngot:  \[1 2 \.\.\. \(8 more bytes\)\nwant: nil$`)
+		testCase(t, tt, gotwant.Len(long, 2, ch.Options()...), `got:  \[1 2 \.\.\. \(8 more bytes\)$`)
+		testCase(t, tt, gotwant.Sorted([]string{"bbbbbbb", "a"}, func(a, b string) bool { return a < b }, ch.Options()...), `: bbbbb\.\.\. \(2 more bytes\), a\s*got:  \[bbbb\.\.\. \(6 more bytes\)$`)
+		testCase(t, tt, gotwant.Greater(1234567, 7654321, ch.Options()...), `^\ngot:  12345\.\.\. \(2 more bytes\)\nwant: > 76543\.\.\. \(2 more bytes\)$`)
+
+		// around the first difference
+		testCase(t, tt, gotwant.ElementsMatch(long, []int{1, 2, 3, 4, 5, 7}, ch.Options()...), `got:  \(9 more bytes\) \.\.\.5 6\]\s*want: \(9 more bytes\) \.\.\.5 7\]$`)
+		c := make(chan int, 1)
+		c <- 1234567
+		testCase(t, tt, gotwant.Receive(c, 1234568, ch.Options()...), `^\ngot:  \(4 more bytes\) \.\.\.567\nwant: \(4 more bytes\) \.\.\.568$`)
+	})
+
+	t.Run("Comparer", func(t *testing.T) {
+		ch := gotwant.NewChecker()
+		gotwant.RegisterComparer(ch, func(got, want time.Time) bool {
+			return got.Equal(want)
+		})
+
+		now := time.Now()
+		utc := now.UTC()
+
+		testCase(t, tt, ch.Case(now, utc), "")
+		testCase(t, tt, ch.Case(now, utc.Add(time.Second)), `got:`)
+
+		// not registered
+		testCase(t, tt, gotwant.Case(now, utc, gotwant.Default.Options()...), `got:`)
+
+		// interfaces never match dynamic types
+		testCase(t, tt, gotwant.Panic(func() {
+			gotwant.RegisterComparer(ch, func(got, want fmt.Stringer) bool { return true })
+		}, "interface type fmt.Stringer"), "")
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		ch := gotwant.NewChecker()
+		ch.Timeout = 10 * time.Millisecond
+
+		testCase(t, tt, gotwant.Receive(make(chan int), 1, ch.Options()...), `^\ngot NO value in 10ms\.\nwant: 1$`)
+		testCase(t, tt, gotwant.Receive(make(chan int), 1, ch.Options(gotwant.Timeout(20*time.Millisecond))...), `^\ngot NO value in 20ms\.\nwant: 1$`)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		saved := *gotwant.Default
+		defer func() { *gotwant.Default = saved }()
+
+		gotwant.Default.MaxLen = 5
+		gotwant.Default.Timeout = 10 * time.Millisecond
+
+		tt.Reset()
+		gotwant.Test(tt, strings.Repeat("a", 10), "b")
+		if r := tt.buf.String(); r != "\ngot:  aaaaa... (5 more bytes)\nwant: b" {
+			t.Error(r)
+		}
+
+		tt.Reset()
+		gotwant.TestReceive(tt, make(chan int), 1)
+		if r := tt.buf.String(); r != "\ngot NO value in 10ms.\nwant: 1" {
+			t.Error(r)
+		}
+
+		// globals are read on every call
+		diffDefault := gotwant.DiffDefault
+		defer func() { gotwant.DiffDefault = diffDefault }()
+		gotwant.DiffDefault = true
+		gotwant.Default.MaxLen = 0
+
+		tt.Reset()
+		gotwant.Test(tt, "a b", "a c")
+		if r := tt.buf.String(); !strings.HasSuffix(r, "diff: a [-b-]{+c+}") {
+			t.Error(r)
+		}
+	})
+}
+
 func TestHowToWrite(t *testing.T) {
 	t.Run("Comparation", func(t *testing.T) {
 		gotwant.Test(t, 1, 1)
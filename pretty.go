@@ -0,0 +1,407 @@
+package gotwant
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FmtPretty is a special format, printing composite values in multiple lines.
+//
+//   - one field (element) per line, nested with indentation
+//   - map keys are sorted
+//   - repeated elements are elided
+//   - pointers are dereferenced, and shared ones are labeled (#1) and referred (<same as #1>)
+//   - cycles are detected
+//
+// It is used as the default for large composite values.
+const FmtPretty = "pretty"
+
+const (
+	// prettyWidth is the max width of one-line composite values printed by the default format.
+	prettyWidth = 80
+
+	prettyIndent = "    "
+)
+
+// sprint formats v by valfmt. valfmt may be FmtPretty.
+func sprint(valfmt string, v interface{}) string {
+	if valfmt == FmtPretty {
+		return pretty(v)
+	}
+	return fmt.Sprintf(valfmt, v)
+}
+
+// pretty formats v in multiple lines.
+func pretty(v interface{}) string {
+	rv := reflect.ValueOf(v)
+
+	p := &prettyPrinter{
+		visiting: make(map[visitKey]bool),
+		refs:     make(map[visitKey]int),
+		ids:      make(map[visitKey]int),
+		nextID:   new(int),
+	}
+	countRefs(rv, p.refs, make(map[visitKey]bool))
+	p.print(rv, 0)
+	return p.sb.String()
+}
+
+// countRefs counts references to each pointer and map in v.
+func countRefs(v reflect.Value, refs map[visitKey]int, seenSlices map[visitKey]bool) {
+	if !v.IsValid() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			countRefs(v.Elem(), refs, seenSlices)
+		}
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		refs[key]++
+		if refs[key] == 1 {
+			countRefs(v.Elem(), refs, seenSlices)
+		}
+
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		refs[key]++
+		if refs[key] == 1 {
+			iter := v.MapRange()
+			for iter.Next() {
+				countRefs(iter.Key(), refs, seenSlices)
+				countRefs(iter.Value(), refs, seenSlices)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return
+			}
+			key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+			if seenSlices[key] {
+				return
+			}
+			seenSlices[key] = true
+		}
+		for i := 0; i < v.Len(); i++ {
+			countRefs(v.Index(i), refs, seenSlices)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			countRefs(v.Field(i), refs, seenSlices)
+		}
+	}
+}
+
+// hasNestedPointer tells whether v contains non-nil pointers inside (not at the top level),
+// which are printed as addresses by fmt.
+func hasNestedPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	refs := make(map[visitKey]int)
+	countRefs(rv, refs, make(map[visitKey]bool))
+	for key := range refs {
+		if key.typ.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
+
+// isComposite tells whether v is (a pointer to) a struct, map, slice or array.
+func isComposite(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type prettyPrinter struct {
+	sb strings.Builder
+
+	visiting map[visitKey]bool // on the current path, for cycle detection
+	refs     map[visitKey]int  // number of references, counted by countRefs
+	ids      map[visitKey]int  // labels of shared references
+	nextID   *int
+
+	noIDs bool // for map keys, which are printed in random order
+}
+
+func (p *prettyPrinter) print(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		p.sb.WriteString("nil")
+		return
+	}
+
+	if s, ok := stringerOf(v); ok {
+		fmt.Fprintf(&p.sb, "%s(%s)", v.Type(), strconv.Quote(s))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			p.sb.WriteString("nil")
+			return
+		}
+		p.print(v.Elem(), depth)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			fmt.Fprintf(&p.sb, "(%s)(nil)", v.Type())
+			return
+		}
+		if !p.enter(v, true) {
+			return
+		}
+		defer p.leave(v)
+
+		p.sb.WriteString("&")
+		p.print(v.Elem(), depth)
+
+	case reflect.Struct:
+		p.sb.WriteString(v.Type().String())
+		if v.NumField() == 0 {
+			p.sb.WriteString("{}")
+			return
+		}
+		p.sb.WriteString("{\n")
+		for i := 0; i < v.NumField(); i++ {
+			p.writeIndent(depth + 1)
+			p.sb.WriteString(v.Type().Field(i).Name)
+			p.sb.WriteString(": ")
+			p.print(v.Field(i), depth+1)
+			p.sb.WriteString(",\n")
+		}
+		p.writeIndent(depth)
+		p.sb.WriteString("}")
+
+	case reflect.Map:
+		if v.IsNil() {
+			fmt.Fprintf(&p.sb, "%s(nil)", v.Type())
+			return
+		}
+		if !p.enter(v, true) {
+			return
+		}
+		defer p.leave(v)
+
+		p.sb.WriteString(v.Type().String())
+		if v.Len() == 0 {
+			p.sb.WriteString("{}")
+			return
+		}
+
+		type entry struct {
+			key   string
+			value reflect.Value
+		}
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries = append(entries, entry{
+				key:   p.subKey(iter.Key(), depth+1),
+				value: iter.Value(),
+			})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].key < entries[j].key
+		})
+
+		p.sb.WriteString("{\n")
+		for _, e := range entries {
+			p.writeIndent(depth + 1)
+			p.sb.WriteString(e.key)
+			p.sb.WriteString(": ")
+			p.print(e.value, depth+1)
+			p.sb.WriteString(",\n")
+		}
+		p.writeIndent(depth)
+		p.sb.WriteString("}")
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				fmt.Fprintf(&p.sb, "%s(nil)", v.Type())
+				return
+			}
+			if !p.enter(v, false) {
+				return
+			}
+			defer p.leave(v)
+		}
+
+		p.sb.WriteString(v.Type().String())
+
+		elems := make([]string, v.Len())
+		inline := isScalarKind(v.Type().Elem().Kind())
+		width := 0
+		for i := range elems {
+			elems[i] = p.sub(v.Index(i), depth+1)
+			width += len(elems[i]) + 2
+		}
+		if len(elems) == 0 || inline && width <= prettyWidth {
+			p.sb.WriteString("{" + strings.Join(elems, ", ") + "}")
+			return
+		}
+
+		p.sb.WriteString("{\n")
+		for i := 0; i < len(elems); {
+			n := 1
+			for i+n < len(elems) && elems[i+n] == elems[i] {
+				n++
+			}
+
+			p.writeIndent(depth + 1)
+			p.sb.WriteString(elems[i])
+			p.sb.WriteString(",\n")
+			if n > 1 {
+				p.writeIndent(depth + 1)
+				fmt.Fprintf(&p.sb, "// ... repeated %d more time(s)\n", n-1)
+			}
+
+			i += n
+		}
+		p.writeIndent(depth)
+		p.sb.WriteString("}")
+
+	case reflect.String:
+		p.sb.WriteString(strconv.Quote(v.String()))
+
+	case reflect.Bool:
+		p.sb.WriteString(strconv.FormatBool(v.Bool()))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.sb.WriteString(strconv.FormatInt(v.Int(), 10))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.sb.WriteString(strconv.FormatUint(v.Uint(), 10))
+
+	case reflect.Float32, reflect.Float64:
+		p.sb.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+
+	case reflect.Complex64, reflect.Complex128:
+		p.sb.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+
+	default: // Chan, Func, UnsafePointer
+		if v.IsNil() {
+			fmt.Fprintf(&p.sb, "(%s)(nil)", v.Type())
+			return
+		}
+		// addresses differ run by run
+		fmt.Fprintf(&p.sb, "(%s)(non-nil)", v.Type())
+	}
+}
+
+// sub prints v into a separated string.
+func (p *prettyPrinter) sub(v reflect.Value, depth int) string {
+	sub := *p
+	sub.sb = strings.Builder{}
+	sub.print(v, depth)
+	return sub.sb.String()
+}
+
+// subKey prints a map key v into a separated string, without labeling.
+func (p *prettyPrinter) subKey(v reflect.Value, depth int) string {
+	sub := *p
+	sub.sb = strings.Builder{}
+	sub.noIDs = true
+	sub.print(v, depth)
+	return sub.sb.String()
+}
+
+func (p *prettyPrinter) writeIndent(depth int) {
+	p.sb.WriteString(strings.Repeat(prettyIndent, depth))
+}
+
+// enter marks v as visiting.
+// If v is a cycle or an already printed shared reference, it writes a reference instead and returns false.
+// If shared and labeled are true, it writes a label of v.
+func (p *prettyPrinter) enter(v reflect.Value, labeled bool) bool {
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	id := p.ids[key]
+
+	if p.visiting[key] {
+		if id != 0 {
+			fmt.Fprintf(&p.sb, "<cycle #%d>", id)
+		} else {
+			fmt.Fprintf(&p.sb, "<cycle %s>", v.Type())
+		}
+		return false
+	}
+
+	if labeled && !p.noIDs {
+		if id != 0 {
+			fmt.Fprintf(&p.sb, "<same as #%d>", id)
+			return false
+		}
+		if p.refs[key] > 1 {
+			*p.nextID++
+			p.ids[key] = *p.nextID
+			fmt.Fprintf(&p.sb, "#%d ", *p.nextID)
+		}
+	}
+
+	p.visiting[key] = true
+	return true
+}
+
+func (p *prettyPrinter) leave(v reflect.Value) {
+	delete(p.visiting, visitKey{ptr: v.Pointer(), typ: v.Type()})
+}
+
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
+
+// stringerOf returns a message of an error or a fmt.Stringer (except for pointers, which may be nil).
+func stringerOf(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || !v.CanInterface() {
+		return "", false
+	}
+
+	switch s := v.Interface().(type) {
+	case error:
+		return s.Error(), true
+	case fmt.Stringer:
+		return s.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,328 @@
+package gotwant
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IgnorePaths sets paths not compared in JSON and other document cases.
+// A path is like $.a.b[0], and * matches any key or index ($.items[*].id).
+func IgnorePaths(paths ...string) Option {
+	return func(c TestCase) {
+		if ic, ok := c.(interface{ SetIgnorePaths([]string) }); ok {
+			ic.SetIgnorePaths(paths)
+		}
+	}
+}
+
+// AllowExtra allows extra fields of objects in got, in JSON and other document cases.
+func AllowExtra() Option {
+	return func(c TestCase) {
+		if ac, ok := c.(interface{ SetAllowExtra(bool) }); ok {
+			ac.SetAllowExtra(true)
+		}
+	}
+}
+
+// normalizeTree converts v decoded by an unmarshaler (JSON, YAML, TOML, ...) into
+// a tree of map[string]interface{}, []interface{} and scalars (string, bool, json.Number, nil).
+func normalizeTree(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case nil, string, bool, json.Number:
+		return vv
+	case time.Time:
+		return vv.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return vv.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = normalizeTree(iter.Value().Interface())
+		}
+		return m
+
+	case reflect.Slice, reflect.Array:
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+		a := make([]interface{}, rv.Len())
+		for i := range a {
+			a[i] = normalizeTree(rv.Index(i).Interface())
+		}
+		return a
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10))
+
+	case reflect.Float32, reflect.Float64:
+		return json.Number(strconv.FormatFloat(rv.Float(), 'g', -1, 64))
+
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeTree(rv.Elem().Interface())
+
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// treeComparer compares trees of map[string]interface{}, []interface{} and scalars.
+type treeComparer struct {
+	ignore     [][]string
+	allowExtra bool
+
+	diffs []string
+}
+
+// compare compares got and want at path, and appends differences to c.diffs.
+func (c *treeComparer) compare(path []string, got, want interface{}) {
+	if c.ignored(path) {
+		return
+	}
+
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			c.report(path, got, want)
+			return
+		}
+
+		for _, k := range sortedKeys(w) {
+			p := appendPath(path, k)
+			gv, found := g[k]
+			if !found {
+				if !c.ignored(p) {
+					c.diffs = append(c.diffs, fmt.Sprintf("%s: missing (want %s)", formatPath(p), scalarString(w[k])))
+				}
+				continue
+			}
+			c.compare(p, gv, w[k])
+		}
+		if !c.allowExtra {
+			for _, k := range sortedKeys(g) {
+				p := appendPath(path, k)
+				if _, found := w[k]; !found && !c.ignored(p) {
+					c.diffs = append(c.diffs, fmt.Sprintf("%s: unexpected (got %s)", formatPath(p), scalarString(g[k])))
+				}
+			}
+		}
+
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			c.report(path, got, want)
+			return
+		}
+
+		for i := 0; i < len(w) || i < len(g); i++ {
+			p := appendPath(path, "["+strconv.Itoa(i)+"]")
+			switch {
+			case i >= len(g):
+				if !c.ignored(p) {
+					c.diffs = append(c.diffs, fmt.Sprintf("%s: missing (want %s)", formatPath(p), scalarString(w[i])))
+				}
+			case i >= len(w):
+				if !c.ignored(p) {
+					c.diffs = append(c.diffs, fmt.Sprintf("%s: unexpected (got %s)", formatPath(p), scalarString(g[i])))
+				}
+			default:
+				c.compare(p, g[i], w[i])
+			}
+		}
+
+	case json.Number:
+		g, ok := got.(json.Number)
+		if !ok || !numberEqual(g, w) {
+			c.report(path, got, want)
+		}
+
+	default:
+		if !reflect.DeepEqual(got, want) {
+			c.report(path, got, want)
+		}
+	}
+}
+
+// test compares got and want, and reports differences followed by got and want rendered by render.
+func (c *treeComparer) test(t T, desc string, got, want interface{}, render func(interface{}) string) {
+	t.Helper()
+
+	c.compare(nil, got, want)
+	if len(c.diffs) == 0 {
+		return
+	}
+
+	t.Errorf("%s\n%s\n%s\n%s", desc, strings.Join(c.diffs, "\n"),
+		indent("got:  "+render(got)), indent("want: "+render(want)))
+}
+
+func (c *treeComparer) report(path []string, got, want interface{}) {
+	c.diffs = append(c.diffs, fmt.Sprintf("%s: got %s, want %s", formatPath(path), scalarString(got), scalarString(want)))
+}
+
+func (c *treeComparer) ignored(path []string) bool {
+	for _, pattern := range c.ignore {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// numberEqual compares numbers exactly, not as float64s (1.0 == 1, but 9007199254740993 != 9007199254740992).
+func numberEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	ra, oka := new(big.Rat).SetString(string(a))
+	rb, okb := new(big.Rat).SetString(string(b))
+	return oka && okb && ra.Cmp(rb) == 0
+}
+
+// scalarString prints a scalar as JSON, and abbreviates objects and arrays.
+func scalarString(v interface{}) string {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		return fmt.Sprintf("an object of %d field(s)", len(vv))
+	case []interface{}:
+		return fmt.Sprintf("an array of %d element(s)", len(vv))
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendPath returns a new path, not sharing the backing array with path.
+func appendPath(path []string, elem string) []string {
+	p := make([]string, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, elem)
+}
+
+// formatPath formats a path like $.a.b[0].
+// Keys not valid as identifiers are quoted like $["a b"].
+func formatPath(path []string) string {
+	sb := &strings.Builder{}
+	sb.WriteString("$")
+	for _, e := range path {
+		switch {
+		case strings.HasPrefix(e, "["):
+			sb.WriteString(e)
+		case isIdent(e):
+			sb.WriteString("." + e)
+		default:
+			sb.WriteString("[" + strconv.Quote(e) + "]")
+		}
+	}
+	return sb.String()
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || r == '-' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || i > 0 && '0' <= r && r <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// parsePaths parses paths like $.a.b[0] or a.b[*] into elements.
+func parsePaths(paths []string) [][]string {
+	results := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		results = append(results, parsePath(p))
+	}
+	return results
+}
+
+func parsePath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+
+	var elems []string
+	for path != "" {
+		switch {
+		case strings.HasPrefix(path, "."):
+			path = path[1:]
+
+		case strings.HasPrefix(path, `["`):
+			end := strings.Index(path, `"]`)
+			if end == -1 {
+				return append(elems, path)
+			}
+			key, err := strconv.Unquote(path[1 : end+1])
+			if err != nil {
+				key = path[2:end]
+			}
+			elems = append(elems, key)
+			path = path[end+2:]
+
+		case strings.HasPrefix(path, "["):
+			end := strings.Index(path, "]")
+			if end == -1 {
+				return append(elems, path)
+			}
+			elems = append(elems, path[:end+1])
+			path = path[end+1:]
+
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			elems = append(elems, path[:end])
+			path = path[end:]
+		}
+	}
+	return elems
+}
+
+// matchPath tells whether path is pattern or under pattern.
+// * and [*] in pattern match any key or index.
+func matchPath(pattern, path []string) bool {
+	if len(path) < len(pattern) {
+		return false
+	}
+	for i, e := range pattern {
+		switch {
+		case e == path[i]:
+		case e == "*":
+		case e == "[*]" && strings.HasPrefix(path[i], "["):
+		default:
+			return false
+		}
+	}
+	return true
+}